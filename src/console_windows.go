@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"log"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+var procAttachConsole = windows.NewLazySystemDLL("kernel32.dll").NewProc("AttachConsole")
+
+// attachConsole lets the subcommands print to the terminal they were started from.
+// The release build is a GUI program, so it doesn't get a console of its own.
+func attachConsole() {
+	if handle, err := windows.GetStdHandle(windows.STD_OUTPUT_HANDLE); err == nil && handle != 0 && handle != windows.InvalidHandle {
+		// already redirected to a file or pipe
+		return
+	}
+	const attachParentProcess = ^uintptr(0)
+	if ret, _, _ := procAttachConsole.Call(attachParentProcess); ret == 0 {
+		return
+	}
+	if out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+		os.Stdout, os.Stderr = out, out
+		log.SetOutput(out)
+	}
+}
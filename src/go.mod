@@ -6,8 +6,10 @@ toolchain go1.24.2
 
 require (
 	gioui.org v0.10.1
+	github.com/Microsoft/go-winio v0.6.2
 	github.com/getlantern/systray v1.2.2
 	github.com/go-ole/go-ole v1.3.0
+	github.com/jezek/xgb v1.1.1
 	golang.org/x/sys v0.39.0
 )
 
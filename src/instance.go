@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"winfastnav/internal/apps"
+	"winfastnav/internal/documents"
+	"winfastnav/internal/ipc"
+	"winfastnav/ui"
+)
+
+// parseArgs turns the command line into the requests for the launcher, by default just show.
+func parseArgs(args []string) ([]ipc.Request, error) {
+	flags := flag.NewFlagSet("winfastnav", flag.ContinueOnError)
+	show := flags.Bool("show", false, "show the launcher")
+	hide := flags.Bool("hide", false, "hide the launcher")
+	mode := flags.String("mode", "", "show the launcher in a mode: programs, documents, internet, windows or gpt")
+	query := flags.String("query", "", "show the launcher and type this query")
+	reindex := flags.Bool("reindex", false, "re-index programs and documents")
+	quit := flags.Bool("quit", false, "quit the running instance")
+	inspect := flags.Bool("inspect", false, "open the developer inspector with the live state and recent commands")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	var requests []ipc.Request
+	if *reindex {
+		requests = append(requests, ipc.Request{Command: ipc.CommandReindex})
+	}
+	switch {
+	case *query != "":
+		requests = append(requests, ipc.Request{Command: ipc.CommandSetQuery, Mode: *mode, Query: *query})
+	case *mode != "":
+		requests = append(requests, ipc.Request{Command: ipc.CommandSetMode, Mode: *mode})
+	case *show:
+		requests = append(requests, ipc.Request{Command: ipc.CommandShow})
+	}
+	if *hide {
+		requests = append(requests, ipc.Request{Command: ipc.CommandHide})
+	}
+	if *inspect {
+		requests = append(requests, ipc.Request{Command: ipc.CommandInspect})
+	}
+	if *quit {
+		requests = append(requests, ipc.Request{Command: ipc.CommandQuit})
+	}
+	if len(requests) == 0 {
+		requests = append(requests, ipc.Request{Command: ipc.CommandShow})
+	}
+
+	for _, req := range requests {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return requests, nil
+}
+
+// handleRequest carries out a request from another invocation, or from our own command line.
+func handleRequest(req ipc.Request) error {
+	switch req.Command {
+	case ipc.CommandShow:
+		ui.ShowWindow()
+	case ipc.CommandHide:
+		ui.HideWindow()
+	case ipc.CommandSetMode:
+		ui.ShowWindowMode(ipc.Modes[req.Mode])
+	case ipc.CommandSetQuery:
+		if req.Mode != "" {
+			ui.ShowWindowMode(ipc.Modes[req.Mode])
+		}
+		ui.SetQuery(req.Query)
+	case ipc.CommandReindex:
+		go documents.SetupDocs()
+		go apps.SetupApps()
+	case ipc.CommandInspect:
+		ui.Inspect()
+	case ipc.CommandQuit:
+		// the server has answered already, the process exits inside Quit
+		ui.Quit()
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/llm"
+	"winfastnav/internal/settings"
+)
+
+// how many replies the model gets to settle on an answer or an action
+const maxRounds = 6
+
+// results listed per search, enough to choose from without filling the context
+const maxListed = 15
+
+// Capabilities are the launcher features the model can use.
+type Capabilities interface {
+	FindApps(query string) []g.Resource
+	// FindDocuments matches names containing query, with the extension (".pdf") and modified
+	// within the duration when those are set.
+	FindDocuments(query, extension string, modifiedWithin time.Duration) []g.Resource
+	Windows() ([]Window, error)
+	Calculate(expression string) (string, error)
+}
+
+// Window is an open window the model can pick. ID is its handle, which stays the same while
+// the window is open, even if its title changes.
+type Window struct {
+	ID    uint64
+	Title string
+}
+
+type ActionKind string
+
+const (
+	ActionOpenApp      ActionKind = "open_app"
+	ActionOpenDocument ActionKind = "open_document"
+	ActionFocusWindow  ActionKind = "focus_window"
+	ActionWebSearch    ActionKind = "web_search"
+)
+
+// Action is what the model decided to do. Nothing runs until the user confirms it.
+// Target is the app's command line, the document path, the window title or the search terms,
+// and Window the handle of the window to focus.
+type Action struct {
+	Kind   ActionKind
+	Name   string
+	Target string
+	Window uint64
+}
+
+// Describe phrases the action as the question the user confirms.
+func (a Action) Describe() string {
+	switch a.Kind {
+	case ActionOpenApp:
+		return "Open " + a.Name + "?"
+	case ActionOpenDocument:
+		return "Open " + a.Name + "?\n" + a.Target
+	case ActionFocusWindow:
+		return "Switch to " + a.Name + "?"
+	default:
+		return "Search the web for " + a.Target + "?"
+	}
+}
+
+// Result is either an answer to show, or an action to confirm.
+type Result struct {
+	Answer string
+	Action *Action
+}
+
+// Router turns a request in plain words into an action through the model's tool calls.
+type Router struct {
+	Client       llm.ToolCaller
+	Capabilities Capabilities
+	// Now is used to tell the model the date, so "yesterday" means something.
+	Now func() time.Time
+}
+
+// New returns a router using the configured model and the launcher's own indexes.
+func New() *Router {
+	return &Router{Client: llm.NewOpenAI(GetConfig()), Capabilities: Local{}, Now: time.Now}
+}
+
+// GetConfig returns the "commandllm" setting, falling back to the Quick GPT provider.
+// Routing needs a model that supports function calling, which not every chat model does.
+func GetConfig() llm.Config {
+	config := llm.GetConfig()
+	if unparsed, err := settings.GetSetting("commandllm"); err == nil && unparsed != "" {
+		var custom llm.Config
+		if err = json.Unmarshal([]byte(unparsed), &custom); err != nil {
+			log.Printf("Error parsing command LLM settings: %v", err)
+		} else if err = custom.Validate(); err != nil {
+			log.Printf("Ignoring command LLM settings: %v", err)
+		} else {
+			config = custom
+		}
+	}
+	// the router writes its own instructions
+	config.SystemPrompt = ""
+	return config
+}
+
+func (r *Router) instructions() string {
+	return "You operate a desktop launcher for the user. Today is " + r.Now().Format("Monday, 2 January 2006") + ".\n" +
+		"Use the search tools to find what the user refers to, then call exactly one of open_app, open_document, " +
+		"focus_window or web_search with a value taken from the search results. Don't guess names or paths. " +
+		"For arithmetic or unit conversions, use calculate and reply with the result. " +
+		"If nothing matches, reply briefly in plain text saying so."
+}
+
+// Route asks the model what the request means, running the searches it asks for until it
+// settles on an action or replies in words.
+func (r *Router) Route(ctx context.Context, request string) (Result, error) {
+	messages := []llm.ToolMessage{
+		{Role: llm.RoleSystem, Content: r.instructions()},
+		{Role: llm.RoleUser, Content: request},
+	}
+	for round := 0; round < maxRounds; round++ {
+		reply, err := r.Client.CallTools(ctx, messages, tools)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(reply.ToolCalls) == 0 {
+			if reply.Content == "" {
+				return Result{}, fmt.Errorf("the model returned no answer")
+			}
+			return Result{Answer: reply.Content}, nil
+		}
+
+		messages = append(messages, reply)
+		for _, call := range reply.ToolCalls {
+			output, action := r.run(call)
+			if action != nil {
+				return Result{Action: action}, nil
+			}
+			messages = append(messages, llm.ToolMessage{Role: llm.RoleTool, ToolCallID: call.ID, Content: output})
+		}
+	}
+	return Result{}, fmt.Errorf("no decision after %d steps, try saying it differently", maxRounds)
+}
+
+type arguments struct {
+	Query              string  `json:"query"`
+	Extension          string  `json:"extension"`
+	ModifiedWithinDays float64 `json:"modified_within_days"`
+	Name               string  `json:"name"`
+	Path               string  `json:"path"`
+	Title              string  `json:"title"`
+	Expression         string  `json:"expression"`
+}
+
+// run performs a tool call. Searches return their output for the model, the launching
+// tools return an action instead, once their target is known to exist.
+func (r *Router) run(call llm.ToolCall) (string, *Action) {
+	var args arguments
+	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+		return "Invalid arguments: " + err.Error(), nil
+	}
+
+	switch call.Name {
+	case "search_apps":
+		return listResources(r.Capabilities.FindApps(args.Query), false), nil
+	case "search_documents":
+		within := time.Duration(args.ModifiedWithinDays * float64(24*time.Hour))
+		return listResources(r.Capabilities.FindDocuments(args.Query, normalizeExtension(args.Extension), within), true), nil
+	case "list_windows":
+		windows, err := r.Capabilities.Windows()
+		if err != nil {
+			return "Error: " + err.Error(), nil
+		}
+		if len(windows) == 0 {
+			return "No windows are open.", nil
+		}
+		titles := make([]string, len(windows))
+		for i, w := range windows {
+			titles[i] = w.Title
+		}
+		return strings.Join(titles, "\n"), nil
+	case "calculate":
+		result, err := r.Capabilities.Calculate(args.Expression)
+		if err != nil {
+			return "Error: " + err.Error(), nil
+		}
+		return result, nil
+	case "open_app":
+		for _, app := range r.Capabilities.FindApps(args.Name) {
+			if strings.EqualFold(app.Name, args.Name) {
+				return "", &Action{Kind: ActionOpenApp, Name: app.Name, Target: app.Filepath}
+			}
+		}
+		return fmt.Sprintf("No app is named %q. Use search_apps and pass a name from its results.", args.Name), nil
+	case "open_document":
+		for _, doc := range r.Capabilities.FindDocuments("", normalizeExtension(filepath.Ext(args.Path)), 0) {
+			if doc.Filepath == args.Path {
+				return "", &Action{Kind: ActionOpenDocument, Name: doc.Name, Target: doc.Filepath}
+			}
+		}
+		return fmt.Sprintf("%q isn't an indexed document. Use search_documents and pass a path from its results.", args.Path), nil
+	case "focus_window":
+		windows, err := r.Capabilities.Windows()
+		if err != nil {
+			return "Error: " + err.Error(), nil
+		}
+		for _, w := range windows {
+			if w.Title == args.Title {
+				return "", &Action{Kind: ActionFocusWindow, Name: w.Title, Target: w.Title, Window: w.ID}
+			}
+		}
+		for _, w := range windows {
+			if args.Title != "" && strings.Contains(strings.ToLower(w.Title), strings.ToLower(args.Title)) {
+				return "", &Action{Kind: ActionFocusWindow, Name: w.Title, Target: w.Title, Window: w.ID}
+			}
+		}
+		return fmt.Sprintf("No window is titled %q. Use list_windows and pass a title from its results.", args.Title), nil
+	case "web_search":
+		if strings.TrimSpace(args.Query) == "" {
+			return "The query is empty.", nil
+		}
+		return "", &Action{Kind: ActionWebSearch, Name: args.Query, Target: args.Query}
+	}
+	return fmt.Sprintf("There is no tool named %q.", call.Name), nil
+}
+
+func listResources(found []g.Resource, withPaths bool) string {
+	if len(found) == 0 {
+		return "Nothing found."
+	}
+	var b strings.Builder
+	for i, resource := range found {
+		if i == maxListed {
+			fmt.Fprintf(&b, "(%d more, search more precisely to see them)\n", len(found)-maxListed)
+			break
+		}
+		if withPaths {
+			fmt.Fprintf(&b, "%s | %s\n", resource.Name, resource.Filepath)
+		} else {
+			b.WriteString(resource.Name + "\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
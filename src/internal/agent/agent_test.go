@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/llm"
+)
+
+// scripted replays the model's replies and records what it was sent.
+type scripted struct {
+	replies []llm.ToolMessage
+	seen    [][]llm.ToolMessage
+}
+
+func (s *scripted) CallTools(_ context.Context, messages []llm.ToolMessage, _ []llm.Tool) (llm.ToolMessage, error) {
+	s.seen = append(s.seen, messages)
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, nil
+}
+
+func call(name string, args map[string]any) llm.ToolMessage {
+	data, _ := json.Marshal(args)
+	return llm.ToolMessage{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "call_" + name, Name: name, Arguments: string(data)}}}
+}
+
+type fakeCapabilities struct {
+	apps, docs []g.Resource
+	windows    []Window
+	searched   []string
+}
+
+func (f *fakeCapabilities) FindApps(query string) []g.Resource {
+	var found []g.Resource
+	for _, app := range f.apps {
+		if strings.Contains(strings.ToLower(app.Name), strings.ToLower(query)) {
+			found = append(found, app)
+		}
+	}
+	return found
+}
+
+func (f *fakeCapabilities) FindDocuments(query, extension string, modifiedWithin time.Duration) []g.Resource {
+	f.searched = append(f.searched, query+"|"+extension+"|"+modifiedWithin.String())
+	var found []g.Resource
+	for _, doc := range f.docs {
+		if strings.Contains(doc.Name, query) && strings.HasSuffix(doc.Filepath, extension) {
+			found = append(found, doc)
+		}
+	}
+	return found
+}
+
+func (f *fakeCapabilities) Windows() ([]Window, error) { return f.windows, nil }
+
+func (f *fakeCapabilities) Calculate(expression string) (string, error) { return "42", nil }
+
+func newRouter(client *scripted, capabilities *fakeCapabilities) *Router {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	return &Router{Client: client, Capabilities: capabilities, Now: func() time.Time { return now }}
+}
+
+func TestRouteSearchesThenProposesAction(t *testing.T) {
+	capabilities := &fakeCapabilities{docs: []g.Resource{
+		{Name: "invoice.pdf", Filepath: "/home/u/Downloads/invoice.pdf"},
+		{Name: "notes.txt", Filepath: "/home/u/notes.txt"},
+	}}
+	client := &scripted{replies: []llm.ToolMessage{
+		call("search_documents", map[string]any{"extension": "pdf", "modified_within_days": 2}),
+		call("open_document", map[string]any{"path": "/home/u/Downloads/invoice.pdf"}),
+	}}
+
+	result, err := newRouter(client, capabilities).Route(context.Background(), "open the pdf I downloaded yesterday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Action{Kind: ActionOpenDocument, Name: "invoice.pdf", Target: "/home/u/Downloads/invoice.pdf"}
+	if result.Action == nil || *result.Action != want {
+		t.Fatalf("result = %+v", result)
+	}
+	if capabilities.searched[0] != "|.pdf|48h0m0s" {
+		t.Errorf("searched with %q", capabilities.searched[0])
+	}
+
+	second := client.seen[1]
+	if !strings.Contains(second[0].Content, "Monday, 19 October 2026") {
+		t.Errorf("instructions don't mention the date: %q", second[0].Content)
+	}
+	if last := second[len(second)-1]; last.Role != llm.RoleTool || last.ToolCallID != "call_search_documents" || !strings.Contains(last.Content, "invoice.pdf | /home/u/Downloads/invoice.pdf") {
+		t.Errorf("tool result sent as %+v", last)
+	}
+}
+
+func TestRouteRejectsTargetsThatDontExist(t *testing.T) {
+	capabilities := &fakeCapabilities{windows: []Window{{ID: 7, Title: "general - Slack"}, {ID: 9, Title: "Inbox - Mail"}}, apps: []g.Resource{{Name: "Slack", Filepath: "slack"}}}
+	client := &scripted{replies: []llm.ToolMessage{
+		call("open_app", map[string]any{"name": "Slak"}),
+		call("focus_window", map[string]any{"title": "slack"}),
+	}}
+
+	result, err := newRouter(client, capabilities).Route(context.Background(), "switch to my Slack window")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Action == nil || *result.Action != (Action{Kind: ActionFocusWindow, Name: "general - Slack", Target: "general - Slack", Window: 7}) {
+		t.Fatalf("result = %+v", result)
+	}
+	if feedback := client.seen[1][len(client.seen[1])-1].Content; !strings.Contains(feedback, "No app is named") {
+		t.Errorf("the model wasn't told the app doesn't exist: %q", feedback)
+	}
+}
+
+func TestRouteAnswersInWords(t *testing.T) {
+	client := &scripted{replies: []llm.ToolMessage{
+		call("calculate", map[string]any{"expression": "6*7"}),
+		{Role: llm.RoleAssistant, Content: "6 times 7 is 42."},
+	}}
+	result, err := newRouter(client, &fakeCapabilities{}).Route(context.Background(), "what's 6 times 7")
+	if err != nil || result.Action != nil || result.Answer != "6 times 7 is 42." {
+		t.Fatalf("got %+v %v", result, err)
+	}
+}
+
+func TestRouteGivesUp(t *testing.T) {
+	client := &scripted{}
+	for i := 0; i < maxRounds; i++ {
+		client.replies = append(client.replies, call("list_windows", nil))
+	}
+	if _, err := newRouter(client, &fakeCapabilities{}).Route(context.Background(), "hmm"); err == nil {
+		t.Fatal("expected an error after too many rounds")
+	}
+}
+
+func TestLocalCalculateConvertsUnits(t *testing.T) {
+	for expression, want := range map[string]string{
+		"5ft to cm": "152.40 cm",
+		"30c to f":  "86 °F",
+		"2+2*3":     "8",
+	} {
+		got, err := Local{}.Calculate(expression)
+		if err != nil || !strings.Contains(got, want) {
+			t.Errorf("Calculate(%q) = %q, %v, want it to contain %q", expression, got, err, want)
+		}
+	}
+}
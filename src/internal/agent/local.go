@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"winfastnav/internal/apps"
+	"winfastnav/internal/documents"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/utils"
+)
+
+// Local runs the tools against the launcher's indexes and the window manager.
+type Local struct{}
+
+func (Local) FindApps(query string) []g.Resource {
+	return apps.FindAppResults(query)
+}
+
+func (Local) FindDocuments(query, extension string, modifiedWithin time.Duration) []g.Resource {
+	query = strings.ToLower(query)
+	var found []g.Resource
+	for _, doc := range documents.Documents() {
+		if !strings.Contains(strings.ToLower(doc.Name), query) {
+			continue
+		}
+		if extension != "" && !strings.EqualFold(filepath.Ext(doc.Filepath), extension) {
+			continue
+		}
+		if modifiedWithin > 0 {
+			info, err := os.Stat(doc.Filepath)
+			if err != nil || time.Since(info.ModTime()) > modifiedWithin {
+				continue
+			}
+		}
+		found = append(found, doc)
+	}
+	return found
+}
+
+// Windows lists the titles for the model, without the icons GetOpenWindows asks each window for.
+func (Local) Windows() ([]Window, error) {
+	wm, err := apps.WindowManager()
+	if err != nil {
+		return nil, err
+	}
+	open, err := wm.List()
+	if err != nil {
+		return nil, err
+	}
+	var windows []Window
+	for _, w := range open {
+		if w.Title != g.AppName {
+			windows = append(windows, Window{ID: w.ID, Title: w.Title})
+		}
+	}
+	return windows, nil
+}
+
+// Calculate converts a quantity the way "=" does in the launcher, listing it in every related
+// unit, so the "to cm" of "5ft to cm" is only dropped. Anything else is evaluated as arithmetic.
+func (Local) Calculate(expression string) (string, error) {
+	quantity, _, _ := strings.Cut(strings.ToLower(expression), " to ")
+	if utils.HasUnit(quantity) {
+		if converted := utils.ConvertUnit(quantity); converted != "" {
+			return converted, nil
+		}
+	}
+	return utils.EvalMath(strings.ReplaceAll(expression, " ", ""))
+}
@@ -0,0 +1,63 @@
+package agent
+
+import "winfastnav/internal/llm"
+
+func object(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// tools are the launcher's capabilities as the model sees them.
+var tools = []llm.Tool{
+	{
+		Name:        "search_apps",
+		Description: "Search the installed applications by name. Returns matching app names.",
+		Parameters:  object(map[string]any{"query": stringProperty("Part of the app name, such as \"slack\"")}, "query"),
+	},
+	{
+		Name:        "search_documents",
+		Description: "Search the indexed documents. Returns name | path for each match. All parameters are optional filters.",
+		Parameters: object(map[string]any{
+			"query":                stringProperty("Part of the file name"),
+			"extension":            stringProperty("File extension, such as \"pdf\""),
+			"modified_within_days": map[string]any{"type": "number", "description": "Only files changed in the last N days, 1 for today and yesterday"},
+		}),
+	},
+	{
+		Name:        "list_windows",
+		Description: "List the titles of the open windows.",
+		Parameters:  object(map[string]any{}),
+	},
+	{
+		Name:        "calculate",
+		Description: "Evaluate arithmetic (2+2*3) or convert units (5ft to cm, 30c to f). Conversions list the quantity in every related unit.",
+		Parameters:  object(map[string]any{"expression": stringProperty("The expression")}, "expression"),
+	},
+	{
+		Name:        "open_app",
+		Description: "Launch an application, after the user confirms.",
+		Parameters:  object(map[string]any{"name": stringProperty("Exact app name from search_apps")}, "name"),
+	},
+	{
+		Name:        "open_document",
+		Description: "Open a document with its default program, after the user confirms.",
+		Parameters:  object(map[string]any{"path": stringProperty("Exact path from search_documents")}, "path"),
+	},
+	{
+		Name:        "focus_window",
+		Description: "Switch to an open window, after the user confirms.",
+		Parameters:  object(map[string]any{"title": stringProperty("Window title from list_windows")}, "title"),
+	},
+	{
+		Name:        "web_search",
+		Description: "Search the web with the default search engine, after the user confirms.",
+		Parameters:  object(map[string]any{"query": stringProperty("What to search for")}, "query"),
+	},
+}
@@ -2,22 +2,48 @@ package apps
 
 import (
 	"log"
-	"os/exec"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
-	"syscall"
 	g "winfastnav/internal/globals"
+	"winfastnav/internal/indexcache"
 )
 
-var appListMu sync.RWMutex
+var (
+	appListMu sync.RWMutex
+	// every installed app, including blocked ones, so unblocking doesn't need a re-index
+	allApps []g.Resource
+)
 
 func SetupApps() {
-	log.Printf("Indexing Windows apps")
+	log.Printf("Indexing apps")
 	appList := GetInstalledApps()
+	setApps(appList)
+	log.Printf("Apps indexed")
+	if err := indexcache.Save("apps", appList); err != nil {
+		log.Printf("Error caching apps: %v", err)
+	}
+}
+
+// LoadCachedApps uses the list saved by the last SetupApps, and reports false if there's none.
+func LoadCachedApps() bool {
+	appList, ok, err := indexcache.Load("apps")
+	if err != nil {
+		log.Printf("Error reading cached apps: %v", err)
+	}
+	if !ok || err != nil {
+		return false
+	}
+	setApps(appList)
+	return true
+}
+
+func setApps(appList []g.Resource) {
 	appListMu.Lock()
-	g.AppList = appList
+	allApps = appList
+	g.AppList = filterBlocked(appList, g.ExecBlocklist)
 	appListMu.Unlock()
-	log.Printf("Windows apps indexed")
 }
 
 func FindAppResults(needle string) []g.Resource {
@@ -39,10 +65,32 @@ func FindAppResults(needle string) []g.Resource {
 	return results
 }
 
-func OpenProgram(execPath string) error {
-	cmd := exec.Command(execPath)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow: true,
+// ProgramFor finds the indexed app whose executable is named process, returning its command line.
+func ProgramFor(process string) (string, bool) {
+	appListMu.RLock()
+	defer appListMu.RUnlock()
+	for _, app := range g.AppList {
+		if strings.EqualFold(executableName(app.Filepath), process) {
+			return app.Filepath, true
+		}
+	}
+	return "", false
+}
+
+// executableName returns the program file of a path or command line without its folder or ".exe".
+func executableName(commandLine string) string {
+	path := strings.Trim(commandLine, `"`)
+	if _, err := os.Stat(path); err != nil {
+		// a command line with arguments, or a quoted path followed by them
+		if quoted, _, ok := strings.Cut(strings.TrimPrefix(commandLine, `"`), `"`); ok && strings.HasPrefix(commandLine, `"`) {
+			path = quoted
+		} else if fields := strings.Fields(commandLine); len(fields) > 0 {
+			path = fields[0]
+		}
+	}
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
 	}
-	return cmd.Start()
+	return name
 }
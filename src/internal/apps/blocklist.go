@@ -0,0 +1,198 @@
+package apps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/settings"
+)
+
+var (
+	regexCache   = map[string]*regexp.Regexp{}
+	regexCacheMu sync.Mutex
+)
+
+// ValidateRule checks the rule has a known kind and a pattern that compiles.
+func ValidateRule(rule g.BlockRule) error {
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return errors.New("the pattern is empty")
+	}
+	switch rule.Kind {
+	case g.BlockPath, g.BlockName:
+		return nil
+	case g.BlockGlob:
+		if _, err := path.Match(normalizeGlob(rule.Pattern), ""); err != nil {
+			return fmt.Errorf("invalid glob: %w", err)
+		}
+		return nil
+	case g.BlockRegex:
+		if _, err := regexp.Compile("(?i)" + rule.Pattern); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown rule kind %q", rule.Kind)
+}
+
+// IsBlocked reports whether any rule matches the application.
+func IsBlocked(app g.Resource, rules []g.BlockRule) bool {
+	for _, rule := range rules {
+		if matchesRule(rule, app) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRule(rule g.BlockRule, app g.Resource) bool {
+	switch rule.Kind {
+	case g.BlockPath:
+		return strings.EqualFold(app.Filepath, rule.Pattern)
+	case g.BlockName:
+		return strings.EqualFold(app.Name, rule.Pattern)
+	case g.BlockGlob:
+		matched, err := path.Match(normalizeGlob(rule.Pattern), normalizeGlob(app.Filepath))
+		return err == nil && matched
+	case g.BlockRegex:
+		re := compiledRegex(rule.Pattern)
+		return re != nil && (re.MatchString(app.Filepath) || re.MatchString(app.Name))
+	}
+	return false
+}
+
+// normalizeGlob makes glob patterns and paths comparable: lowercase, with forward slashes.
+func normalizeGlob(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, `\`, "/"))
+}
+
+func compiledRegex(pattern string) *regexp.Regexp {
+	regexCacheMu.Lock()
+	defer regexCacheMu.Unlock()
+	if re, ok := regexCache[pattern]; ok {
+		return re
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		log.Printf("Invalid blocklist regex %q: %v", pattern, err)
+		re = nil
+	}
+	regexCache[pattern] = re
+	return re
+}
+
+// BlockApplication hides the application by its exact path. added is false when a rule
+// already did, then there is nothing to undo. Otherwise the returned rule can be passed to
+// UnblockRule to undo it.
+func BlockApplication(application g.Resource) (rule g.BlockRule, added bool, err error) {
+	rule = g.BlockRule{Kind: g.BlockPath, Pattern: strings.TrimSpace(application.Filepath)}
+	added, err = addRule(rule)
+	return rule, added, err
+}
+
+// AddBlockRule validates, saves and applies a new rule.
+func AddBlockRule(rule g.BlockRule) error {
+	_, err := addRule(rule)
+	return err
+}
+
+// addRule adds the rule unless an identical one exists, reporting whether it did.
+func addRule(rule g.BlockRule) (bool, error) {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	if err := ValidateRule(rule); err != nil {
+		return false, err
+	}
+
+	appListMu.Lock()
+	defer appListMu.Unlock()
+	for _, existing := range g.ExecBlocklist {
+		if existing.Kind == rule.Kind && strings.EqualFold(existing.Pattern, rule.Pattern) {
+			return false, nil
+		}
+	}
+	if err := saveBlocklist(append(append([]g.BlockRule(nil), g.ExecBlocklist...), rule)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveBlockRule deletes the rule at index, bringing back the apps only it was hiding.
+func RemoveBlockRule(index int) error {
+	appListMu.Lock()
+	defer appListMu.Unlock()
+	if index < 0 || index >= len(g.ExecBlocklist) {
+		return fmt.Errorf("no blocklist entry at position %d", index)
+	}
+	rules := append(append([]g.BlockRule(nil), g.ExecBlocklist[:index]...), g.ExecBlocklist[index+1:]...)
+	return saveBlocklist(rules)
+}
+
+// UnblockRule deletes the given rule if it's still in the blocklist.
+func UnblockRule(rule g.BlockRule) error {
+	appListMu.Lock()
+	defer appListMu.Unlock()
+	for i, existing := range g.ExecBlocklist {
+		if existing == rule {
+			rules := append(append([]g.BlockRule(nil), g.ExecBlocklist[:i]...), g.ExecBlocklist[i+1:]...)
+			return saveBlocklist(rules)
+		}
+	}
+	return nil
+}
+
+// BlockedApps returns the indexed applications the rule hides.
+func BlockedApps(rule g.BlockRule) []g.Resource {
+	appListMu.RLock()
+	defer appListMu.RUnlock()
+	var blocked []g.Resource
+	for _, app := range allApps {
+		if matchesRule(rule, app) {
+			blocked = append(blocked, app)
+		}
+	}
+	return blocked
+}
+
+// Blocklist returns a copy of the current rules.
+func Blocklist() []g.BlockRule {
+	appListMu.RLock()
+	defer appListMu.RUnlock()
+	return append([]g.BlockRule(nil), g.ExecBlocklist...)
+}
+
+func UnblockAllApplications() {
+	appListMu.Lock()
+	defer appListMu.Unlock()
+	if err := saveBlocklist([]g.BlockRule{}); err != nil {
+		log.Printf("Error saving settings: %v", err)
+	}
+}
+
+// saveBlocklist persists the rules and refilters the app list. appListMu must be held.
+func saveBlocklist(rules []g.BlockRule) error {
+	jsonData, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("encoding blocklist: %w", err)
+	}
+	if err = settings.SetSetting("blocklist", string(jsonData)); err != nil {
+		return fmt.Errorf("saving blocklist: %w", err)
+	}
+	g.ExecBlocklist = rules
+	g.AppList = filterBlocked(allApps, rules)
+	return nil
+}
+
+func filterBlocked(apps []g.Resource, rules []g.BlockRule) []g.Resource {
+	var visible []g.Resource
+	for _, app := range apps {
+		if !IsBlocked(app, rules) {
+			visible = append(visible, app)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,71 @@
+package apps
+
+import (
+	"testing"
+	g "winfastnav/internal/globals"
+)
+
+func TestBlockRulesMatchOnlyWhatTheyDescribe(t *testing.T) {
+	editor := g.Resource{Name: "Code Editor", Filepath: `c:\apps\editor\editor.exe`}
+	helper := g.Resource{Name: "Editor Helper", Filepath: `c:\apps\editor\helper.exe`}
+	other := g.Resource{Name: "Calculator", Filepath: `c:\apps\calc.exe`}
+
+	cases := []struct {
+		rule    g.BlockRule
+		matches []g.Resource
+	}{
+		// the old substring filter hid every path containing the blocked one
+		{g.BlockRule{Kind: g.BlockPath, Pattern: `c:\apps`}, nil},
+		{g.BlockRule{Kind: g.BlockPath, Pattern: `C:\Apps\Editor\Editor.exe`}, []g.Resource{editor}},
+		{g.BlockRule{Kind: g.BlockName, Pattern: "calculator"}, []g.Resource{other}},
+		{g.BlockRule{Kind: g.BlockGlob, Pattern: `c:\apps\editor\*.exe`}, []g.Resource{editor, helper}},
+		{g.BlockRule{Kind: g.BlockGlob, Pattern: `c:\apps\*.exe`}, []g.Resource{other}},
+		{g.BlockRule{Kind: g.BlockRegex, Pattern: `helper`}, []g.Resource{helper}},
+	}
+
+	for _, c := range cases {
+		for _, app := range []g.Resource{editor, helper, other} {
+			want := false
+			for _, m := range c.matches {
+				want = want || m == app
+			}
+			if got := matchesRule(c.rule, app); got != want {
+				t.Errorf("%+v on %q: got %v, want %v", c.rule, app.Filepath, got, want)
+			}
+		}
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := []g.BlockRule{
+		{Kind: g.BlockPath, Pattern: `c:\a.exe`},
+		{Kind: g.BlockGlob, Pattern: `c:\*\*.exe`},
+		{Kind: g.BlockRegex, Pattern: `^c:\\a`},
+		{Kind: g.BlockName, Pattern: "App"},
+	}
+	for _, rule := range valid {
+		if err := ValidateRule(rule); err != nil {
+			t.Errorf("%+v: unexpected error %v", rule, err)
+		}
+	}
+
+	invalid := []g.BlockRule{
+		{Kind: g.BlockPath, Pattern: " "},
+		{Kind: g.BlockGlob, Pattern: `c:\[`},
+		{Kind: g.BlockRegex, Pattern: `(`},
+		{Kind: "substring", Pattern: "a"},
+	}
+	for _, rule := range invalid {
+		if err := ValidateRule(rule); err == nil {
+			t.Errorf("%+v should be rejected", rule)
+		}
+	}
+}
+
+func TestFilterBlocked(t *testing.T) {
+	apps := []g.Resource{{Name: "A", Filepath: "a.exe"}, {Name: "B", Filepath: "b.exe"}}
+	visible := filterBlocked(apps, []g.BlockRule{{Kind: g.BlockName, Pattern: "a"}})
+	if len(visible) != 1 || visible[0].Name != "B" {
+		t.Fatalf("unexpected visible apps: %+v", visible)
+	}
+}
@@ -0,0 +1,88 @@
+package apps
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore reports whether the characters of pattern appear in text in order, ignoring case,
+// and scores the match: consecutive characters, word starts and an early first match score higher.
+func FuzzyScore(pattern, text string) (int, bool) {
+	needle := []rune(strings.ToLower(pattern))
+	if len(needle) == 0 {
+		return 0, true
+	}
+	haystack := []rune(text)
+
+	score, matched, last := 0, 0, -2
+	for i, r := range haystack {
+		if matched == len(needle) {
+			break
+		}
+		if unicode.ToLower(r) != needle[matched] {
+			continue
+		}
+		score++
+		if i == last+1 {
+			score += 5
+		}
+		if wordStart(haystack, i) {
+			score += 8
+		}
+		if matched == 0 {
+			// a match further into the text is less likely to be what was meant
+			score -= min(i, 10)
+		}
+		last = i
+		matched++
+	}
+	return score, matched == len(needle)
+}
+
+// wordStart reports whether haystack[i] begins a word, after a separator or as a capital after a lowercase letter.
+func wordStart(haystack []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	previous, current := haystack[i-1], haystack[i]
+	if !unicode.IsLetter(previous) && !unicode.IsDigit(previous) {
+		return true
+	}
+	return unicode.IsLower(previous) && unicode.IsUpper(current)
+}
+
+// MatchPositions returns the indexes of the runes of text that query matched, ignoring case:
+// the first place query appears whole, otherwise the characters FuzzyScore matched. It's nil
+// when query doesn't match.
+func MatchPositions(query, text string) []int {
+	needle := []rune(strings.ToLower(strings.TrimSpace(query)))
+	haystack := []rune(text)
+	if len(needle) == 0 {
+		return nil
+	}
+	lowered := make([]rune, len(haystack))
+	for i, r := range haystack {
+		lowered[i] = unicode.ToLower(r)
+	}
+	for start := 0; start+len(needle) <= len(lowered); start++ {
+		if slices.Equal(lowered[start:start+len(needle)], needle) {
+			positions := make([]int, len(needle))
+			for i := range positions {
+				positions[i] = start + i
+			}
+			return positions
+		}
+	}
+
+	var positions []int
+	for i, r := range lowered {
+		if len(positions) < len(needle) && r == needle[len(positions)] {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) < len(needle) {
+		return nil
+	}
+	return positions
+}
@@ -0,0 +1,88 @@
+package apps
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFuzzyScoreMatchesCharactersInOrder(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"", "anything", true},
+		{"vsc", "Visual Studio Code", true},
+		{"CODE", "Visual Studio Code", true},
+		{"cdoe", "Visual Studio Code", false},
+		{"fox", "Mozilla Firefox", true},
+		{"xyz", "Mozilla Firefox", false},
+	} {
+		if _, ok := FuzzyScore(tc.pattern, tc.text); ok != tc.want {
+			t.Errorf("FuzzyScore(%q, %q) matched = %v, want %v", tc.pattern, tc.text, ok, tc.want)
+		}
+	}
+}
+
+func TestFuzzyScorePrefersWordStartsAndRuns(t *testing.T) {
+	initials, _ := FuzzyScore("vsc", "Visual Studio Code")
+	scattered, _ := FuzzyScore("vsc", "obvious scheme")
+	if initials <= scattered {
+		t.Errorf("initials scored %d, scattered letters %d", initials, scattered)
+	}
+	run, _ := FuzzyScore("term", "Terminal")
+	spread, _ := FuzzyScore("term", "The empire remembers")
+	if run <= spread {
+		t.Errorf("consecutive match scored %d, spread match %d", run, spread)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	for _, tc := range []struct {
+		query, text string
+		want        []int
+	}{
+		{"", "Terminal", nil},
+		{"port", "Quarterly report", []int{12, 13, 14, 15}},
+		{"REP", "Quarterly report", []int{10, 11, 12}},
+		{"mzf", "Mozilla Firefox", []int{0, 2, 8}},
+		{"ñu", "Año nuevo", []int{1, 5}},
+		{"xyz", "Mozilla Firefox", nil},
+	} {
+		if got := MatchPositions(tc.query, tc.text); !slices.Equal(got, tc.want) {
+			t.Errorf("MatchPositions(%q, %q) = %v, want %v", tc.query, tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestFilterWindows(t *testing.T) {
+	windows := []OpenWindow{
+		{Number: 1, Title: "Inbox - Outlook", Process: "OUTLOOK"},
+		{Number: 2, Title: "main.go - winfastnav - Visual Studio Code", Process: "Code"},
+		{Number: 3, Title: "Mozilla Firefox", Process: "firefox"},
+		{Number: 4, Title: "2048", Process: "game"},
+	}
+	numbers := func(list []OpenWindow) []int {
+		var n []int
+		for _, w := range list {
+			n = append(n, w.Number)
+		}
+		return n
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{1, 2, 3, 4}},
+		{"fire", []int{3}},
+		{"code", []int{2}},
+		{"outlk", []int{1}},
+		{"2", []int{2}},
+		{"2048", []int{4}},
+		{"zzz", nil},
+	} {
+		if got := numbers(FilterWindows(windows, tc.query)); !slices.Equal(got, tc.want) {
+			t.Errorf("FilterWindows(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
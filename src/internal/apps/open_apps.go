@@ -1,122 +1,149 @@
 package apps
 
 import (
-	"fmt"
-	"golang.org/x/sys/windows"
+	"errors"
+	"image"
 	"sort"
-	"syscall"
-	"unsafe"
+	"strconv"
+	"strings"
+	"sync"
 	g "winfastnav/internal/globals"
+	"winfastnav/internal/windowmanager"
 )
 
 var (
-	user32                  = windows.NewLazySystemDLL("user32.dll")
-	procEnumWindows         = user32.NewProc("EnumWindows")
-	procGetWindowTextLength = user32.NewProc("GetWindowTextLengthW")
-	procGetWindowText       = user32.NewProc("GetWindowTextW")
-	procIsWindowVisible     = user32.NewProc("IsWindowVisible")
-	procShowWindow          = user32.NewProc("ShowWindow")
-	procIsIconic            = user32.NewProc("IsIconic")
-	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
-	lastOpenWindows         map[int]HWND
+	windowsMu     sync.Mutex
+	windowManager windowmanager.WindowManager
 )
 
-type HWND windows.Handle
-
-// used for sort
-type windowEntry struct {
-	handle HWND
-	title  string
+// manager connects to the window manager on first use.
+func manager() (windowmanager.WindowManager, error) {
+	if windowManager != nil {
+		return windowManager, nil
+	}
+	wm, err := windowmanager.New()
+	if err != nil {
+		return nil, err
+	}
+	windowManager = wm
+	return wm, nil
 }
 
-func GetOpenWindows() []string {
-	var windowsMap = make(map[HWND]string)
-
-	callback := syscall.NewCallback(func(hwnd uintptr, lparam uintptr) uintptr {
-		h := HWND(hwnd)
-		if isWindowVisible(h) {
-			title := getWindowText(h)
-			if len(title) > 0 {
-				windowsMap[h] = title
-			}
-		}
-		return 1
-	})
+// OpenWindow is a window offered by the switcher. ID is the window handle, which stays valid
+// however the list changes, and Number is the position typed as a shortcut.
+type OpenWindow struct {
+	ID      uint64
+	Number  int
+	Title   string
+	Process string
+	Icon    image.Image
+}
 
-	_, _, _ = procEnumWindows.Call(callback, 0)
+// WindowManager returns the window manager the switcher uses.
+func WindowManager() (windowmanager.WindowManager, error) {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	return manager()
+}
 
-	lastOpenWindows = map[int]HWND{}
-	var switchWindowRet []string
-	count := 1
+// GetOpenWindows lists the switchable windows with their icons, the most recently used first.
+func GetOpenWindows() ([]OpenWindow, error) {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
 
-	// move to a windowEntry struct for sorting
-	entries := make([]windowEntry, 0, len(windowsMap))
-	for hwnd, title := range windowsMap {
-		entries = append(entries, windowEntry{hwnd, title})
+	wm, err := manager()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := wm.List()
+	if err != nil {
+		return nil, err
 	}
 
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].title < entries[j].title
-	})
-
+	var openWindows []OpenWindow
 	for _, entry := range entries {
-		if entry.title == g.AppName {
+		if entry.Title == g.AppName {
 			continue
 		}
+		openWindows = append(openWindows, OpenWindow{ID: entry.ID, Number: len(openWindows) + 1, Title: entry.Title, Process: entry.Process, Icon: wm.Icon(entry.ID)})
+	}
+	return openWindows, nil
+}
 
-		lastOpenWindows[count] = entry.handle
-
-		// for unicode safety we use runes and not chars
-		runes := []rune(entry.title)
-		var showTitle string
-
-		if len(runes) > 64 {
-			showTitle = string(runes[:64])
-		} else {
-			showTitle = entry.title
+// FilterWindows keeps the windows whose title or process fuzzy-matches query, best match first.
+// A query that is the number of a window picks that window alone.
+func FilterWindows(windows []OpenWindow, query string) []OpenWindow {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return windows
+	}
+	if n, err := strconv.Atoi(query); err == nil {
+		for _, w := range windows {
+			if w.Number == n {
+				return []OpenWindow{w}
+			}
 		}
+	}
 
-		switchWindowRet = append(switchWindowRet, fmt.Sprintf("[ %d ] %s", count, showTitle))
-		count++
+	type scored struct {
+		window OpenWindow
+		score  int
+	}
+	var matches []scored
+	for _, w := range windows {
+		titleScore, titleOK := FuzzyScore(query, w.Title)
+		processScore, processOK := FuzzyScore(query, w.Process)
+		switch {
+		case titleOK && processOK:
+			matches = append(matches, scored{w, max(titleScore, processScore)})
+		case titleOK:
+			matches = append(matches, scored{w, titleScore})
+		case processOK:
+			matches = append(matches, scored{w, processScore})
+		}
 	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
 
-	return switchWindowRet
+	filtered := make([]OpenWindow, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.window
+	}
+	return filtered
 }
 
-func isWindowMinimized(hwnd HWND) bool {
-	ret, _, _ := procIsIconic.Call(uintptr(hwnd))
-	return ret != 0
-}
+// WindowAction performs the action on the window with this handle, if it is still open.
+func WindowAction(id uint64, action windowmanager.Action) error {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
 
-func FocusWindow(windowNumber int) {
-	if windowNumber > 0 && windowNumber <= len(lastOpenWindows) {
-		h := lastOpenWindows[windowNumber]
-		// only restore if minimized
-		if isWindowMinimized(h) {
-			_, _, _ = procShowWindow.Call(uintptr(h), uintptr(9))
+	wm, err := manager()
+	if err != nil {
+		return err
+	}
+	entries, err := wm.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return windowmanager.Perform(wm, id, action)
 		}
-		setForegroundWindow(h)
 	}
+	return errors.New("the window was closed")
 }
 
-func getWindowText(hwnd HWND) string {
-	length, _, _ := procGetWindowTextLength.Call(uintptr(hwnd))
+// ActiveWindowTitle returns the title of the focused window.
+func ActiveWindowTitle() (string, error) {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
 
-	if length == 0 {
-		return ""
+	wm, err := manager()
+	if err != nil {
+		return "", err
 	}
-
-	buf := make([]uint16, length+1)
-	_, _, _ = procGetWindowText.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&buf[0])), length+1)
-	return syscall.UTF16ToString(buf)
-}
-
-func isWindowVisible(hwnd HWND) bool {
-	ret, _, _ := procIsWindowVisible.Call(uintptr(hwnd))
-	return ret != 0
-}
-
-func setForegroundWindow(hwnd HWND) bool {
-	ret, _, _ := procSetForegroundWindow.Call(uintptr(hwnd))
-	return ret != 0
+	window, err := wm.Active()
+	if err != nil {
+		return "", err
+	}
+	return window.Title, nil
 }
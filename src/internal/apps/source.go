@@ -0,0 +1,57 @@
+package apps
+
+import (
+	"log"
+	"sort"
+	"strings"
+	g "winfastnav/internal/globals"
+)
+
+// AppSource is one place installed applications are listed, such as the registry or .desktop files.
+type AppSource interface {
+	Name() string
+	Apps() ([]g.Resource, error)
+}
+
+// GetInstalledApps lists the apps from every source for this platform, sorted by name.
+func GetInstalledApps() []g.Resource {
+	return collectApps(platformSources())
+}
+
+// OpenProgram starts an app by the command line it was indexed with.
+func OpenProgram(commandLine string) error {
+	_, err := StartProgram(commandLine)
+	return err
+}
+
+// collectApps merges the sources in order. An app whose path an earlier source already listed
+// is not added again; within one source every entry is kept, since the source has already
+// settled its own duplicates (XDG desktop file IDs, for one).
+func collectApps(sources []AppSource) []g.Resource {
+	var apps []g.Resource
+	seen := make(map[string]struct{})
+	for _, source := range sources {
+		found, err := source.Apps()
+		if err != nil {
+			log.Printf("Error listing apps from %s: %v", source.Name(), err)
+		}
+		for _, app := range found {
+			if _, ok := seen[appKey(app)]; !ok {
+				apps = append(apps, app)
+			}
+		}
+		for _, app := range found {
+			seen[appKey(app)] = struct{}{}
+		}
+	}
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		return strings.ToLower(apps[i].Name) < strings.ToLower(apps[j].Name)
+	})
+	return apps
+}
+
+// appKey identifies an app by what it runs. Windows paths ignore case, and so does the key.
+func appKey(app g.Resource) string {
+	return strings.ToLower(app.Filepath)
+}
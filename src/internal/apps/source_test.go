@@ -0,0 +1,57 @@
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	g "winfastnav/internal/globals"
+)
+
+func TestCollectAppsSkipsPathsAnEarlierSourceListed(t *testing.T) {
+	first := fakeSource{{Name: "Editor", Filepath: "/usr/bin/editor"}, {Name: "Editor", Filepath: "/opt/editor"}}
+	second := fakeSource{{Name: "Text editor", Filepath: "/USR/BIN/EDITOR"}, {Name: "Editor", Filepath: "/snap/bin/editor"}}
+
+	got := collectApps([]AppSource{first, second})
+	want := []g.Resource{{Name: "Editor", Filepath: "/usr/bin/editor"}, {Name: "Editor", Filepath: "/opt/editor"}, {Name: "Editor", Filepath: "/snap/bin/editor"}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+type fakeSource []g.Resource
+
+func (fakeSource) Name() string                  { return "fake" }
+func (s fakeSource) Apps() ([]g.Resource, error) { return s, nil }
+
+func TestProgramForMatchesExecutableNames(t *testing.T) {
+	// an installed program in a folder with a space, as under Program Files
+	dir := filepath.Join(t.TempDir(), "My Apps")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	installed := filepath.Join(dir, "Player.exe")
+	if err := os.WriteFile(installed, nil, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	appListMu.Lock()
+	saved := g.AppList
+	g.AppList = []g.Resource{
+		{Name: "Player", Filepath: installed},
+		{Name: "Code", Filepath: "/usr/bin/code --new-window"},
+		{Name: "Files", Filepath: "flatpak run org.gnome.Nautilus"},
+	}
+	appListMu.Unlock()
+	defer func() {
+		appListMu.Lock()
+		g.AppList = saved
+		appListMu.Unlock()
+	}()
+
+	for process, want := range map[string]string{"player": installed, "code": "/usr/bin/code --new-window", "Nautilus": ""} {
+		if got, _ := ProgramFor(process); got != want {
+			t.Errorf("ProgramFor(%q) = %q, want %q", process, got, want)
+		}
+	}
+}
@@ -7,13 +7,43 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	g "winfastnav/internal/globals"
+	"winfastnav/internal/opener"
 	"winfastnav/internal/utils"
 )
 
-func GetInstalledApps() []g.Resource {
+// We only care about programs
+var skipRelease = map[string]struct{}{
+	"hotfix":          {},
+	"security update": {},
+	"service pack":    {},
+	"update":          {},
+}
+
+// We don't care about stuff with these substr either
+var skipIfSubstr = []string{
+	"speech recognition",
+	"redistributable",
+	"x64-based systems",
+	"application verifier",
+	"install",
+	"unins",
+	"sdk",
+	"runtime",
+	"rundll32.exe",
+}
+
+func platformSources() []AppSource {
+	return []AppSource{registrySource{}, startMenuSource{}}
+}
+
+// registrySource reads the Uninstall keys, which list most installed programs with their executable.
+type registrySource struct{}
+
+func (registrySource) Name() string { return "registry" }
+
+func (registrySource) Apps() ([]g.Resource, error) {
 	keys := []registry.Key{
 		registry.LOCAL_MACHINE,
 		registry.CURRENT_USER,
@@ -23,27 +53,6 @@ func GetInstalledApps() []g.Resource {
 		`SOFTWARE\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`,
 	}
 
-	// We only care about programs
-	skipRelease := map[string]struct{}{
-		"hotfix":          {},
-		"security update": {},
-		"service pack":    {},
-		"update":          {},
-	}
-
-	// We don't care about stuff with these substr either
-	skipIfSubstr := []string{
-		"speech recognition",
-		"redistributable",
-		"x64-based systems",
-		"application verifier",
-		"install",
-		"unins",
-		"sdk",
-		"runtime",
-		"rundll32.exe",
-	}
-
 	var apps []g.Resource
 
 	// Somehow not found by default
@@ -100,31 +109,27 @@ func GetInstalledApps() []g.Resource {
 					continue
 				}
 
+				publisher, _, _ := subKey.GetStringValue("Publisher")
 				// Sometimes there's a comma and extra params, clear those out
-				apps = append(apps, g.Resource{Name: strings.TrimSpace(displayName), Filepath: cleanExecutablePath(execPath)})
+				apps = append(apps, g.Resource{Name: strings.TrimSpace(displayName), Filepath: cleanExecutablePath(execPath), Publisher: strings.TrimSpace(publisher)})
 				_ = subKey.Close()
 			}
 		}
 	}
 
-	apps = scanStartMenu(apps)
-
-	var cleanApps []g.Resource
+	return cleanApps(apps), nil
+}
 
-	// remove undesirables
+// cleanApps drops entries that aren't executables or look like installers, updaters and runtimes.
+func cleanApps(apps []g.Resource) []g.Resource {
+	var clean []g.Resource
 	for i, app := range apps {
 		if !(!strings.Contains(app.Filepath, ".exe") || utils.ContainsAny(app.Filepath, skipIfSubstr) ||
-			utils.ContainsAny(strings.ToLower(app.Name), skipIfSubstr) || utils.ContainsAny(app.Filepath, g.ExecBlocklist)) {
-			cleanApps = append(cleanApps, apps[i])
+			utils.ContainsAny(strings.ToLower(app.Name), skipIfSubstr)) {
+			clean = append(clean, apps[i])
 		}
 	}
-
-	// sort by name
-	sort.Slice(cleanApps, func(i, j int) bool {
-		return strings.ToLower(cleanApps[i].Name) < strings.ToLower(cleanApps[j].Name)
-	})
-
-	return cleanApps
+	return clean
 }
 
 func cleanExecutablePath(path string) string {
@@ -167,13 +172,18 @@ func resolveShortcut(path string) (string, error) {
 	return tp.ToString(), nil
 }
 
-// Search for programs by grabbing .lnk's off the start menu
-func scanStartMenu(currentAppList []g.Resource) []g.Resource {
+// startMenuSource finds programs by grabbing .lnk's off the start menu
+type startMenuSource struct{}
+
+func (startMenuSource) Name() string { return "start menu" }
+
+func (startMenuSource) Apps() ([]g.Resource, error) {
 	dirs := []string{
 		filepath.Join(os.Getenv("APPDATA"), "Microsoft", "Windows", "Start Menu", "Programs"),
 		filepath.Join(os.Getenv("PROGRAMDATA"), "Microsoft", "Windows", "Start Menu", "Programs"),
 	}
 
+	var apps []g.Resource
 	for _, base := range dirs {
 		err := filepath.WalkDir(base, func(p string, de fs.DirEntry, err error) error {
 			if err != nil || de.IsDir() || !strings.HasSuffix(strings.ToLower(p), ".lnk") {
@@ -184,20 +194,17 @@ func scanStartMenu(currentAppList []g.Resource) []g.Resource {
 				return nil
 			}
 			name := strings.TrimSuffix(de.Name(), ".lnk")
-
-			// No repeats
-			for _, app := range currentAppList {
-				if strings.EqualFold(app.Filepath, target) || strings.EqualFold(app.Name, name) {
-					return nil
-				}
-			}
-
-			currentAppList = append(currentAppList, g.Resource{Name: strings.TrimSpace(name), Filepath: strings.ToLower(target)})
+			apps = append(apps, g.Resource{Name: strings.TrimSpace(name), Filepath: strings.ToLower(target)})
 			return nil
 		})
 		if err != nil {
-			return nil
+			return nil, err
 		}
 	}
-	return currentAppList
+	return cleanApps(apps), nil
+}
+
+// StartProgram starts an executable found in the registry or the start menu.
+func StartProgram(execPath string) (exited <-chan struct{}, err error) {
+	return opener.Launch(execPath, nil, "")
 }
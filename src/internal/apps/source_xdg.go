@@ -0,0 +1,344 @@
+//go:build !windows
+
+package apps
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/opener"
+)
+
+func platformSources() []AppSource {
+	return []AppSource{desktopSource{
+		dirs:     applicationDirs(),
+		locale:   messagesLocale(),
+		desktops: strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":"),
+	}}
+}
+
+// applicationDirs lists the applications directories in precedence order:
+// the user's data home first, then each entry of XDG_DATA_DIRS.
+func applicationDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	var dirs []string
+	for _, dir := range append([]string{dataHome}, strings.Split(dataDirs, ":")...) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "applications"))
+		}
+	}
+	return dirs
+}
+
+func messagesLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// desktopSource reads the .desktop entries of the freedesktop.org menu.
+type desktopSource struct {
+	dirs     []string
+	locale   string
+	desktops []string
+}
+
+func (desktopSource) Name() string { return "desktop entries" }
+
+func (s desktopSource) Apps() ([]g.Resource, error) {
+	var apps []g.Resource
+	// a desktop file ID found in an earlier directory shadows the later ones, even when it's hidden
+	seen := make(map[string]struct{})
+	for _, dir := range s.dirs {
+		_ = filepath.WalkDir(dir, func(path string, de fs.DirEntry, err error) error {
+			if err != nil || de.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+			id := strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+			if _, ok := seen[id]; ok {
+				return nil
+			}
+			seen[id] = struct{}{}
+
+			entry, err := readDesktopEntry(path)
+			if err != nil {
+				return nil
+			}
+			if app, ok := s.resource(entry, path); ok {
+				apps = append(apps, app)
+			}
+			return nil
+		})
+	}
+	return apps, nil
+}
+
+// resource turns an entry into a launchable app, or reports false if it shouldn't be listed here.
+func (s desktopSource) resource(entry map[string]string, path string) (g.Resource, bool) {
+	if entry["Type"] != "Application" || entry["NoDisplay"] == "true" || entry["Hidden"] == "true" {
+		return g.Resource{}, false
+	}
+	if onlyShowIn := entry["OnlyShowIn"]; onlyShowIn != "" && !s.shownIn(onlyShowIn) {
+		return g.Resource{}, false
+	}
+	if notShowIn := entry["NotShowIn"]; notShowIn != "" && s.shownIn(notShowIn) {
+		return g.Resource{}, false
+	}
+	if tryExec := entry["TryExec"]; tryExec != "" && !isExecutable(tryExec) {
+		return g.Resource{}, false
+	}
+
+	name := localized(entry, "Name", s.locale)
+	if strings.TrimSpace(name) == "" || entry["Exec"] == "" {
+		return g.Resource{}, false
+	}
+	args, err := splitExec(entry["Exec"])
+	if err != nil || len(args) == 0 {
+		return g.Resource{}, false
+	}
+	args = expandFieldCodes(args, name, entry["Icon"], path)
+	if len(args) == 0 {
+		return g.Resource{}, false
+	}
+	return g.Resource{Name: strings.TrimSpace(name), Filepath: joinExec(args)}, true
+}
+
+// shownIn reports whether any desktop in the ;-separated list is the current one.
+func (s desktopSource) shownIn(list string) bool {
+	for _, desktop := range strings.Split(list, ";") {
+		for _, current := range s.desktops {
+			if desktop != "" && strings.EqualFold(desktop, current) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readDesktopEntry returns the keys of the [Desktop Entry] group, with string escapes resolved.
+func readDesktopEntry(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entry := make(map[string]string)
+	inEntry := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inEntry || !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, duplicate := entry[key]; !duplicate {
+			entry[key] = unescapeValue(strings.TrimSpace(value))
+		}
+	}
+	return entry, scanner.Err()
+}
+
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			// not a string escape; leave it for the Exec quoting rules
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// localized picks Key[locale] for a locale such as "de_AT.UTF-8@euro", trying
+// lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER and lang before the unlocalized key.
+func localized(entry map[string]string, key, locale string) string {
+	locale, modifier, _ := strings.Cut(locale, "@")
+	locale, _, _ = strings.Cut(locale, ".")
+	lang, country, _ := strings.Cut(locale, "_")
+
+	var candidates []string
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+	if lang != "" && lang != "C" && lang != "POSIX" {
+		candidates = append(candidates, lang)
+	}
+	for _, candidate := range candidates {
+		if value, ok := entry[key+"["+candidate+"]"]; ok && value != "" {
+			return value
+		}
+	}
+	return entry[key]
+}
+
+// splitExec splits an Exec value into arguments. Arguments with reserved characters
+// are double-quoted, and inside quotes \", \`, \$ and \\ are escapes.
+func splitExec(exec string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(exec) && strings.IndexByte("\"`$\\", exec[i+1]) >= 0:
+			i++
+			current.WriteByte(exec[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", exec)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// expandFieldCodes resolves the % codes of an Exec line. The file and URL codes are dropped
+// since the launcher never passes files, %i, %c and %k become the icon, name and entry path.
+func expandFieldCodes(args []string, name, icon, path string) []string {
+	var expanded []string
+	for _, arg := range args {
+		switch arg {
+		case "%f", "%F", "%u", "%U", "%d", "%D", "%n", "%N", "%v", "%m":
+			continue
+		case "%i":
+			if icon != "" {
+				expanded = append(expanded, "--icon", icon)
+			}
+			continue
+		}
+
+		var b strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' || i+1 == len(arg) {
+				b.WriteByte(arg[i])
+				continue
+			}
+			i++
+			switch arg[i] {
+			case '%':
+				b.WriteByte('%')
+			case 'c':
+				b.WriteString(name)
+			case 'k':
+				b.WriteString(path)
+			}
+		}
+		expanded = append(expanded, b.String())
+	}
+	return expanded
+}
+
+// joinExec quotes the arguments back into a single command line that splitExec reads.
+func joinExec(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+			quoted[i] = arg
+			continue
+		}
+		var b strings.Builder
+		b.WriteByte('"')
+		for j := 0; j < len(arg); j++ {
+			if strings.IndexByte("\"`$\\", arg[j]) >= 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(arg[j])
+		}
+		b.WriteByte('"')
+		quoted[i] = b.String()
+	}
+	return strings.Join(quoted, " ")
+}
+
+// isExecutable resolves TryExec, either an absolute path or a name looked up in PATH.
+func isExecutable(program string) bool {
+	if filepath.IsAbs(program) {
+		info, err := os.Stat(program)
+		return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+	}
+	_, err := exec.LookPath(program)
+	return err == nil
+}
+
+// StartProgram runs a command line built from a desktop entry.
+func StartProgram(commandLine string) (exited <-chan struct{}, err error) {
+	args, err := splitExec(commandLine)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return opener.Launch(args[0], args[1:], "")
+}
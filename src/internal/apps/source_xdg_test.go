@@ -0,0 +1,105 @@
+//go:build !windows
+
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeEntry(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDesktopSourceFiltersEntries(t *testing.T) {
+	user, system := t.TempDir(), t.TempDir()
+
+	writeEntry(t, system, "editor.desktop", "[Desktop Entry]\nType=Application\nName=Editor\nName[de]=Bearbeiter\nName[de_AT]=Editor AT\nExec=editor %F\n")
+	writeEntry(t, system, "hidden.desktop", "[Desktop Entry]\nType=Application\nName=Hidden\nExec=hidden\n")
+	writeEntry(t, user, "hidden.desktop", "[Desktop Entry]\nType=Application\nName=Hidden\nExec=hidden\nHidden=true\n")
+	writeEntry(t, system, "nodisplay.desktop", "[Desktop Entry]\nType=Application\nName=Helper\nExec=helper\nNoDisplay=true\n")
+	writeEntry(t, system, "kde-only.desktop", "[Desktop Entry]\nType=Application\nName=KDE Tool\nExec=kdetool\nOnlyShowIn=KDE;\n")
+	writeEntry(t, system, "gnome-only.desktop", "[Desktop Entry]\nType=Application\nName=GNOME Tool\nExec=gnometool\nOnlyShowIn=GNOME;Unity;\n")
+	writeEntry(t, system, "not-gnome.desktop", "[Desktop Entry]\nType=Application\nName=Not GNOME\nExec=notgnome\nNotShowIn=GNOME;\n")
+	writeEntry(t, system, "missing.desktop", "[Desktop Entry]\nType=Application\nName=Missing\nExec=missing\nTryExec=/nonexistent/missing\n")
+	writeEntry(t, system, "link.desktop", "[Desktop Entry]\nType=Link\nName=Site\nURL=https://example.com\n")
+	writeEntry(t, system, filepath.Join("vendor", "tool.desktop"), "[Desktop Action new]\nName=Wrong\n[Desktop Entry]\nType=Application\nName=Tool\nExec=tool --new-window\n")
+	writeEntry(t, user, "vendor-tool.desktop", "[Desktop Entry]\nType=Application\nName=User Tool\nExec=tool\n")
+
+	source := desktopSource{dirs: []string{user, system}, locale: "de_DE.UTF-8", desktops: []string{"ubuntu", "GNOME"}}
+	apps, err := source.Apps()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	slices.Sort(names)
+	want := []string{"Bearbeiter", "GNOME Tool", "User Tool"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+}
+
+func TestLocalizedName(t *testing.T) {
+	entry := map[string]string{"Name": "Default", "Name[sr]": "Lang", "Name[sr_YU]": "Country", "Name[sr@Latn]": "Modifier"}
+	cases := map[string]string{
+		"sr_YU.UTF-8@Latn": "Country",
+		"sr_YU":            "Country",
+		"sr_CS@Latn":       "Modifier",
+		"sr_CS":            "Lang",
+		"en_US.UTF-8":      "Default",
+		"C":                "Default",
+		"":                 "Default",
+	}
+	for locale, want := range cases {
+		if got := localized(entry, "Name", locale); got != want {
+			t.Errorf("localized(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestExecFieldCodesAndQuoting(t *testing.T) {
+	cases := map[string][]string{
+		`firefox %u`:                                     {"firefox"},
+		`app --name=%c %i %k`:                            {"app", "--name=My App", "--icon", "app-icon", "/apps/app.desktop"},
+		`"/opt/My App/run" --pct=100%% %F`:               {"/opt/My App/run", "--pct=100%"},
+		"sh -c \"echo \\\"hi\\\" \\$HOME \\\\ \\`x\\`\"": {"sh", "-c", "echo \"hi\" $HOME \\ `x`"},
+	}
+	for exec, want := range cases {
+		args, err := splitExec(exec)
+		if err != nil {
+			t.Fatalf("splitExec(%q): %v", exec, err)
+		}
+		got := expandFieldCodes(args, "My App", "app-icon", "/apps/app.desktop")
+		if !slices.Equal(got, want) {
+			t.Errorf("%q expanded to %q, want %q", exec, got, want)
+		}
+
+		again, err := splitExec(joinExec(got))
+		if err != nil || !slices.Equal(again, got) {
+			t.Errorf("joinExec(%q) did not round trip: %q %v", got, again, err)
+		}
+	}
+
+	if _, err := splitExec(`app "unterminated`); err == nil {
+		t.Error("unterminated quote should fail")
+	}
+}
+
+func TestUnescapeValue(t *testing.T) {
+	if got := unescapeValue(`a\sb\tc\\d\$`); got != "a b\tc\\d\\$" {
+		t.Errorf("unexpected unescape: %q", got)
+	}
+}
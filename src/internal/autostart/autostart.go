@@ -0,0 +1,68 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// State is the start-at-login entry as it is saved, not as it was last set from here.
+type State struct {
+	Enabled bool
+	// Path is the program the entry starts.
+	Path string
+	// Stale means the entry starts a different file than the running one, usually because the binary moved.
+	Stale bool
+}
+
+// entry is where one platform keeps the start-at-login command.
+type entry interface {
+	read() (path string, ok bool, err error)
+	write(path string) error
+	remove() error
+}
+
+// executable is the path Enable registers, swapped out in tests.
+var executable = func() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// Enable starts winfastnav at login.
+func Enable() error {
+	path, err := executable()
+	if err != nil {
+		return err
+	}
+	return platformEntry().write(path)
+}
+
+// Disable removes the entry. It is not an error if there isn't one.
+func Disable() error {
+	return platformEntry().remove()
+}
+
+// Status reads the saved entry.
+func Status() (State, error) {
+	path, ok, err := platformEntry().read()
+	if err != nil || !ok {
+		return State{}, err
+	}
+	current, err := executable()
+	if err != nil {
+		return State{}, err
+	}
+	return State{Enabled: true, Path: path, Stale: !samePath(path, current)}, nil
+}
+
+// Refresh points an enabled entry at the running binary, for when it was moved or updated
+// to a new location. It does nothing if start at login is off.
+func Refresh() error {
+	state, err := Status()
+	if err != nil || !state.Stale {
+		return err
+	}
+	return Enable()
+}
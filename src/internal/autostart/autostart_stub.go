@@ -0,0 +1,100 @@
+//go:build !windows
+
+package autostart
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func platformEntry() entry {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return desktopFile{err: err}
+	}
+	return desktopFile{path: filepath.Join(dir, "autostart", "winfastnav.desktop")}
+}
+
+// desktopFile is an XDG autostart entry, which desktop environments run at login.
+type desktopFile struct {
+	path string
+	err  error
+}
+
+func (f desktopFile) read() (string, bool, error) {
+	if f.err != nil {
+		return "", false, f.err
+	}
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	var path string
+	disabled := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		switch key {
+		case "Exec":
+			path = unquote(value)
+		// how the desktops' own startup settings turn an entry off
+		case "Hidden":
+			disabled = disabled || value == "true"
+		case "X-GNOME-Autostart-enabled":
+			disabled = disabled || value == "false"
+		}
+	}
+	if disabled || path == "" {
+		return "", false, scanner.Err()
+	}
+	return path, true, scanner.Err()
+}
+
+func (f desktopFile) write(path string) error {
+	if f.err != nil {
+		return f.err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=winfastnav\nComment=Fast navigation bar\nExec=%s\nTerminal=false\nX-GNOME-Autostart-enabled=true\n", quote(path))
+	return os.WriteFile(f.path, []byte(content), 0o600)
+}
+
+func (f desktopFile) remove() error {
+	if f.err != nil {
+		return f.err
+	}
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// quote follows the Exec quoting rules for a path with spaces or reserved characters.
+func quote(path string) string {
+	if !strings.ContainsAny(path, " \t\"'\\$`") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", `$`, `\\$`)
+	return `"` + r.Replace(path) + `"`
+}
+
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	r := strings.NewReplacer(`\\\\`, `\`, `\\"`, `"`, "\\\\`", "`", `\\$`, `$`)
+	return r.Replace(value[1 : len(value)-1])
+}
+
+func samePath(a, b string) bool { return a == b }
@@ -0,0 +1,88 @@
+//go:build !windows
+
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useExecutable(t *testing.T, path string) {
+	t.Helper()
+	previous := executable
+	executable = func() (string, error) { return path, nil }
+	t.Cleanup(func() { executable = previous })
+}
+
+func TestEnableDisableStatus(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	useExecutable(t, "/opt/win fast nav/winfastnav")
+
+	if state, err := Status(); err != nil || state.Enabled {
+		t.Fatalf("expected disabled, got %+v %v", state, err)
+	}
+	if err := Enable(); err != nil {
+		t.Fatal(err)
+	}
+	state, err := Status()
+	if err != nil || !state.Enabled || state.Stale || state.Path != "/opt/win fast nav/winfastnav" {
+		t.Fatalf("unexpected state %+v %v", state, err)
+	}
+
+	if err := Disable(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Disable(); err != nil {
+		t.Fatalf("disabling twice should not fail: %v", err)
+	}
+	if state, _ := Status(); state.Enabled {
+		t.Fatal("still enabled after Disable")
+	}
+}
+
+func TestRefreshFollowsMovedBinary(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	useExecutable(t, "/old/winfastnav")
+	if err := Enable(); err != nil {
+		t.Fatal(err)
+	}
+
+	useExecutable(t, "/new/winfastnav")
+	if state, _ := Status(); !state.Stale {
+		t.Fatal("moved binary should make the entry stale")
+	}
+	if err := Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := Status(); state.Stale || state.Path != "/new/winfastnav" {
+		t.Fatalf("entry not updated: %+v", state)
+	}
+
+	_ = Disable()
+	if err := Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := Status(); state.Enabled {
+		t.Fatal("Refresh should not enable a disabled entry")
+	}
+}
+
+func TestDisabledByDesktopSettings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "autostart", "winfastnav.desktop")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	content := "[Desktop Entry]\nType=Application\nExec=\"/a \\\\$b\\\\\\\\c\"\nX-GNOME-Autostart-enabled=false\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := Status(); state.Enabled {
+		t.Fatal("X-GNOME-Autostart-enabled=false should count as disabled")
+	}
+	if got := unquote(quote(`/a $b\c`)); got != `/a $b\c` {
+		t.Fatalf("quote did not round trip: %q", got)
+	}
+}
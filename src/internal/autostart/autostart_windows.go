@@ -0,0 +1,61 @@
+//go:build windows
+
+package autostart
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	runKey    = `Software\Microsoft\Windows\CurrentVersion\Run`
+	valueName = "WinFastNav"
+)
+
+func platformEntry() entry { return runValue{} }
+
+// runValue is the value under the current user's Run key.
+type runValue struct{}
+
+func (runValue) read() (string, bool, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", false, err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(valueName)
+	if errors.Is(err, registry.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	// older versions saved the path without quotes
+	return strings.Trim(value, `"`), true, nil
+}
+
+func (runValue) write(path string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetStringValue(valueName, `"`+path+`"`)
+}
+
+func (runValue) remove() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	if err = key.DeleteValue(valueName); errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func samePath(a, b string) bool { return strings.EqualFold(a, b) }
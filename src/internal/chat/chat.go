@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"winfastnav/internal/llm"
+	"winfastnav/internal/settings"
+)
+
+// maximum length of a title taken from the first prompt
+const titleLength = 48
+
+// Chat is one Quick GPT conversation, saved as chats/<ID>.json in the settings folder.
+type Chat struct {
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	Created  time.Time     `json:"created"`
+	Updated  time.Time     `json:"updated"`
+	Messages []llm.Message `json:"messages"`
+}
+
+// Summary is what the chat list shows, without loading every message.
+type Summary struct {
+	ID       string
+	Title    string
+	Updated  time.Time
+	Messages int
+}
+
+var dir = func() (string, error) {
+	base, err := settings.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "chats"), nil
+}
+
+// New starts an empty chat. It isn't saved until it has a message.
+func New() *Chat {
+	now := time.Now()
+	return &Chat{ID: strconv.FormatInt(now.UnixNano(), 36), Created: now, Updated: now}
+}
+
+// Add appends a message. The first user message also becomes the title.
+func (c *Chat) Add(role, content string) {
+	c.Messages = append(c.Messages, llm.Message{Role: role, Content: content})
+	c.Updated = time.Now()
+	if c.Title == "" && role == llm.RoleUser {
+		c.Title = title(content)
+	}
+}
+
+func title(prompt string) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if runes := []rune(prompt); len(runes) > titleLength {
+		return strings.TrimSpace(string(runes[:titleLength-3])) + "..."
+	}
+	return prompt
+}
+
+// Save writes the chat, replacing the previous version.
+func Save(c *Chat) error {
+	if len(c.Messages) == 0 {
+		return nil
+	}
+	chatDir, err := dir()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(chatDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(chatDir, c.ID+".json.tmp")
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(chatDir, c.ID+".json"))
+}
+
+// Load reads a saved chat.
+func Load(id string) (*Chat, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("invalid chat id %q", id)
+	}
+	chatDir, err := dir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(chatDir, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var c Chat
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("chat %s: %w", id, err)
+	}
+	return &c, nil
+}
+
+// List returns the saved chats, most recently updated first. Unreadable files are skipped.
+func List() ([]Summary, error) {
+	chatDir, err := dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(chatDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var chats []Summary
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		c, err := Load(id)
+		if err != nil {
+			continue
+		}
+		chats = append(chats, Summary{ID: c.ID, Title: c.Title, Updated: c.Updated, Messages: len(c.Messages)})
+	}
+	slices.SortFunc(chats, func(a, b Summary) int { return b.Updated.Compare(a.Updated) })
+	return chats, nil
+}
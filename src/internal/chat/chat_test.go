@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"winfastnav/internal/llm"
+)
+
+func useTempDir(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	dir = func() (string, error) { return tmp, nil }
+	return tmp
+}
+
+func TestSaveLoadAndList(t *testing.T) {
+	tmp := useTempDir(t)
+
+	if chats, err := List(); err != nil || len(chats) != 0 {
+		t.Fatalf("expected no chats, got %v %v", chats, err)
+	}
+
+	older := New()
+	older.Add(llm.RoleUser, "What is the capital\nof France?")
+	older.Add(llm.RoleAssistant, "Paris.")
+	older.Updated = time.Now().Add(-time.Hour)
+	newer := New()
+	newer.ID += "b"
+	newer.Add(llm.RoleUser, "Hello")
+	for _, c := range []*Chat{older, newer, New()} {
+		if err := Save(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.WriteFile(filepath.Join(tmp, "broken.json"), []byte("{"), 0o600)
+
+	chats, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, c := range chats {
+		titles = append(titles, c.Title)
+	}
+	if want := []string{"Hello", "What is the capital of France?"}; !slices.Equal(titles, want) {
+		t.Fatalf("titles = %q, want %q", titles, want)
+	}
+
+	loaded, err := Load(older.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.Messages, older.Messages) {
+		t.Errorf("messages = %+v", loaded.Messages)
+	}
+}
+
+func TestLoadRejectsPaths(t *testing.T) {
+	useTempDir(t)
+	for _, id := range []string{"", "../prefs", `..\prefs`, "a.b"} {
+		if _, err := Load(id); err == nil {
+			t.Errorf("Load(%q) should fail", id)
+		}
+	}
+}
+
+func TestTitleIsShortened(t *testing.T) {
+	c := New()
+	c.Add(llm.RoleAssistant, "ignored")
+	c.Add(llm.RoleUser, strings.Repeat("word ", 20))
+	if len([]rune(c.Title)) > titleLength || !strings.HasSuffix(c.Title, "...") {
+		t.Errorf("title %q", c.Title)
+	}
+}
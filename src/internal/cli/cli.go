@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"winfastnav/internal/apps"
+	"winfastnav/internal/core"
+	"winfastnav/internal/documents"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/ipc"
+	"winfastnav/internal/settings"
+)
+
+// commands are the subcommands that run without the window or tray.
+var commands = []string{"query", "eval", "apps", "replay"}
+
+// shorter mode names accepted on the command line, on top of the ipc ones
+var modeAliases = map[string]string{"apps": "programs", "docs": "documents", "web": "internet"}
+
+// IsCommand reports whether name is a headless subcommand.
+func IsCommand(name string) bool {
+	return slices.Contains(commands, name)
+}
+
+type result struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type output struct {
+	Results []result `json:"results"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Run executes a subcommand such as `query --mode docs report` and returns the exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		fmt.Fprintf(stderr, "usage: winfastnav %s ...\n", strings.Join(commands, "|"))
+		return 2
+	}
+
+	flags := flag.NewFlagSet("winfastnav "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print JSON instead of text")
+	reindex := flags.Bool("reindex", false, "index again instead of using the cached index")
+	modeName := "programs"
+	if args[0] == "query" {
+		flags.StringVar(&modeName, "mode", modeName, "programs (apps), documents (docs), internet (web) or gpt")
+	}
+	positional, err := parseInterspersed(flags, args[1:])
+	if err != nil {
+		return 2
+	}
+	text := strings.Join(positional, " ")
+	if args[0] == "replay" {
+		return replay(text, stdout, stderr, *asJSON)
+	}
+
+	settings.SetupSettings()
+
+	var out output
+	switch args[0] {
+	case "query":
+		mode, ok := parseMode(modeName)
+		if !ok {
+			fmt.Fprintf(stderr, "unknown mode %q\n", modeName)
+			return 2
+		}
+		if text == "" {
+			fmt.Fprintln(stderr, "query needs the text to search for")
+			return 2
+		}
+		loadIndex(mode, *reindex)
+		out = run(mode, text)
+	case "eval":
+		if text == "" {
+			fmt.Fprintln(stderr, "eval needs an expression, such as \"2+2\" or \"5ft to cm\"")
+			return 2
+		}
+		if !strings.HasPrefix(text, "=") {
+			text = "=" + text
+		}
+		out = run(g.ModeSearchProgram, text)
+	case "apps":
+		loadIndex(g.ModeSearchProgram, *reindex)
+		for _, app := range g.AppList {
+			out.Results = append(out.Results, result{Name: app.Name, Path: app.Filepath})
+		}
+	}
+
+	if err := write(stdout, out, *asJSON); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses the flags wherever they are among the arguments, as in
+// "query report --json", and returns the other arguments. Those after "--" are kept as they are.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		// Parse stops at the first argument that isn't a flag, or drops "--" and stops after it
+		rest := flags.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" || len(rest) == 0 {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func parseMode(name string) (int, bool) {
+	if alias, ok := modeAliases[name]; ok {
+		name = alias
+	}
+	mode, ok := ipc.Modes[name]
+	// switching windows and running commands need the launcher to confirm them
+	return mode, ok && mode != g.ModeChooseProgram && mode != g.ModeCommand
+}
+
+// loadIndex prepares the index the mode searches, from the cache the launcher saved when there is one.
+func loadIndex(mode int, reindex bool) {
+	switch mode {
+	case g.ModeSearchProgram:
+		if reindex || !apps.LoadCachedApps() {
+			apps.SetupApps()
+		}
+	case g.ModeSearchDocument:
+		if reindex || !documents.LoadCachedDocs() {
+			documents.SetupDocs()
+		}
+	}
+}
+
+// run passes text through the same pipeline as the launcher's search box.
+func run(mode int, text string) output {
+	var out output
+	items, message := core.HandleTextInput(mode, text)
+	for _, item := range items {
+		out.Results = append(out.Results, result{Name: item.Name, Path: item.Filepath})
+	}
+	if message != nil {
+		out.Message = *message
+	}
+	return out
+}
+
+func write(w io.Writer, out output, asJSON bool) error {
+	if asJSON {
+		if out.Results == nil {
+			out.Results = []result{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	for _, r := range out.Results {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", r.Name, r.Path); err != nil {
+			return err
+		}
+	}
+	if out.Message != "" {
+		_, err := fmt.Fprintln(w, out.Message)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/indexcache"
+	"winfastnav/internal/presentation"
+)
+
+func setup(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	if runtime.GOOS == "windows" {
+		t.Setenv("APPDATA", dir)
+	} else {
+		t.Setenv("XDG_CONFIG_HOME", dir)
+	}
+	cached := []g.Resource{{Name: "Code Editor", Filepath: "/usr/bin/editor"}, {Name: "Calculator", Filepath: "/usr/bin/calc"}}
+	if err := indexcache.Save("apps", cached); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryUsesCachedApps(t *testing.T) {
+	setup(t)
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"query", "--mode", "apps", "editor"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "Code Editor\t/usr/bin/editor\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestAppsJSON(t *testing.T) {
+	setup(t)
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"apps", "--json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	var out output
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Results) != 2 || out.Results[0].Name != "Code Editor" {
+		t.Fatalf("unexpected results %+v", out.Results)
+	}
+}
+
+func TestFlagsAfterQueryText(t *testing.T) {
+	setup(t)
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"query", "editor", "--json", "--mode", "apps"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	var out output
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Results) != 1 || out.Results[0].Name != "Code Editor" {
+		t.Fatalf("unexpected results %+v", out.Results)
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "")
+	positional, err := parseInterspersed(flags, []string{"a", "--json", "b", "--", "--json", "c"})
+	if err != nil || !*asJSON || !slices.Equal(positional, []string{"a", "b", "--json", "c"}) {
+		t.Fatalf("parsed %q, json %v, error %v", positional, *asJSON, err)
+	}
+}
+
+func TestEval(t *testing.T) {
+	setup(t)
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"eval", "2+2*3"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "8" {
+		t.Fatalf("unexpected result %q", got)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	setup(t)
+	for _, args := range [][]string{{"query"}, {"query", "--mode", "windows", "x"}, {"query", "--mode", "music", "x"}, {"eval"}, {"apps", "--bogus"}, {"replay"}} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: exit code %d, want 2", args, code)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	controller := presentation.NewController(g.ModeSearchProgram)
+	defer controller.Close()
+	controller.Batch(presentation.Command{Kind: presentation.CommandShow}, presentation.Command{Kind: presentation.CommandSetQuery, Query: "notes"})
+	var saved bytes.Buffer
+	if err := controller.WriteHistory(&saved); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "command log.json")
+	if err := os.WriteFile(path, saved.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"replay", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "2 commands replayed, 0 differ\n") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	changed := strings.Replace(saved.String(), `"Query": "notes"`, `"Query": "other"`, 1)
+	if err := os.WriteFile(path, []byte(changed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if code := Run([]string{"replay", "--json", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit code %d for a log that differs", code)
+	}
+	var steps []replayStep
+	if err := json.Unmarshal(stdout.Bytes(), &steps); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || !steps[0].Matches || steps[1].Matches || steps[1].Command != `SetQuery "other"` {
+		t.Errorf("unexpected steps %+v", steps)
+	}
+}
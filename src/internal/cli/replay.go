@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"winfastnav/internal/presentation"
+)
+
+type replayStep struct {
+	Batch    uint64 `json:"batch"`
+	Command  string `json:"command"`
+	Matches  bool   `json:"matches"`
+	Recorded string `json:"recorded,omitempty"`
+	Replayed string `json:"replayed,omitempty"`
+}
+
+// replay feeds a command log saved by the inspector to a fresh controller and reports the
+// commands that no longer lead to the recorded state. It fails when there is one.
+func replay(path string, stdout, stderr io.Writer, asJSON bool) int {
+	if path == "" {
+		fmt.Fprintln(stderr, "replay needs the command log to read")
+		return 2
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer f.Close()
+	log, err := presentation.ReadLog(f)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var steps []replayStep
+	mismatches := 0
+	for _, step := range presentation.Replay(log) {
+		out := replayStep{Batch: step.Entry.Batch, Command: step.Entry.Command.String(), Matches: step.Matches}
+		if !step.Matches {
+			mismatches++
+			out.Recorded, out.Replayed = fmt.Sprintf("%+v", step.Entry.State), fmt.Sprintf("%+v", step.State)
+		}
+		steps = append(steps, out)
+	}
+
+	if asJSON {
+		if steps == nil {
+			steps = []replayStep{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(steps)
+	} else {
+		for i, step := range steps {
+			status := "ok"
+			if !step.Matches {
+				status = "DIFFERS\n\trecorded " + step.Recorded + "\n\treplayed " + step.Replayed
+			}
+			if _, err = fmt.Fprintf(stdout, "%d\tbatch %d\t%s\t%s\n", i+1, step.Batch, step.Command, status); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			_, err = fmt.Fprintf(stdout, "%d commands replayed, %d differ\n", len(steps), mismatches)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if mismatches > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,6 @@
+package clipboard
+
+import "errors"
+
+// ErrEmpty is returned when the clipboard holds no text, such as after copying an image.
+var ErrEmpty = errors.New("the clipboard has no text")
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// readers are the clipboard tools tried in order, the Wayland one only in a Wayland session.
+func readers() [][]string {
+	var commands [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-paste", "--no-newline", "--type", "text"})
+	}
+	return append(commands,
+		[]string{"xclip", "-selection", "clipboard", "-out"},
+		[]string{"xsel", "--clipboard", "--output"},
+	)
+}
+
+// Text returns the text on the clipboard, read with wl-paste, xclip or xsel.
+func Text() (string, error) {
+	for _, command := range readers() {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		out, err := exec.Command(path, command[1:]...).Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			// the tools exit with an error when there's nothing to paste
+			if errors.As(err, &exitErr) {
+				return "", ErrEmpty
+			}
+			return "", err
+		}
+		if len(out) == 0 {
+			return "", ErrEmpty
+		}
+		return string(out), nil
+	}
+	return "", fmt.Errorf("reading the clipboard needs wl-paste, xclip or xsel")
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const cfUnicodeText = 13
+
+var (
+	user32               = windows.NewLazySystemDLL("user32.dll")
+	kernel32             = windows.NewLazySystemDLL("kernel32.dll")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procGetClipboardData = user32.NewProc("GetClipboardData")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	procLstrlenW         = kernel32.NewProc("lstrlenW")
+	procRtlMoveMemory    = kernel32.NewProc("RtlMoveMemory")
+)
+
+// Text returns the text on the clipboard.
+func Text() (text string, err error) {
+	// the clipboard is opened for the calling thread, and only that thread can close it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if ret, _, openErr := procOpenClipboard.Call(0); ret == 0 {
+		return "", fmt.Errorf("can't open the clipboard: %w", openErr)
+	}
+	defer func() {
+		if ret, _, closeErr := procCloseClipboard.Call(); ret == 0 && err == nil {
+			text, err = "", fmt.Errorf("can't close the clipboard: %w", closeErr)
+		}
+	}()
+
+	handle, _, _ := procGetClipboardData.Call(cfUnicodeText)
+	if handle == 0 {
+		return "", ErrEmpty
+	}
+	data, _, lockErr := procGlobalLock.Call(handle)
+	if data == 0 {
+		return "", fmt.Errorf("can't read the clipboard: %w", lockErr)
+	}
+	defer procGlobalUnlock.Call(handle)
+
+	// copy out of the global memory block, which stays owned by the clipboard
+	length, _, _ := procLstrlenW.Call(data)
+	if length == 0 {
+		return "", ErrEmpty
+	}
+	buf := make([]uint16, length)
+	_, _, _ = procRtlMoveMemory.Call(uintptr(unsafe.Pointer(&buf[0])), data, length*2)
+	return syscall.UTF16ToString(buf), nil
+}
@@ -7,11 +7,12 @@ import (
 
 	"winfastnav/internal/apps"
 	"winfastnav/internal/globals"
-	"winfastnav/internal/settings"
+	"winfastnav/internal/search"
 	"winfastnav/internal/utils"
 )
 
-func HandleTextInput(query string) (retItems []globals.Resource, resultStr *string) {
+// HandleTextInput returns the results or the message for query typed in mode.
+func HandleTextInput(mode int, query string) (retItems []globals.Resource, resultStr *string) {
 	if len(query) == 0 {
 		return nil, nil
 	}
@@ -40,9 +41,18 @@ func HandleTextInput(query string) (retItems []globals.Resource, resultStr *stri
 		return nil, &result
 	}
 
-	switch globals.CurrentMode {
+	// search engine bangs ("!gh winfastnav") work from every search mode, bare keywords in internet search or when turned on
+	if mode != globals.ModeAskGPT && mode != globals.ModeChooseProgram && mode != globals.ModeCommand {
+		if engine, terms, ok := search.Match(query, mode == globals.ModeSearchInternet); ok {
+			s := fmt.Sprintf("%s search: %s", engine.Name, terms)
+			s = utils.WrapTextByWords(s, 64)
+			return nil, &s
+		}
+	}
+
+	switch mode {
 	case globals.ModeSearchInternet:
-		s := fmt.Sprintf("Internet search: %s", query)
+		s := fmt.Sprintf("Internet search (%s): %s", search.Default().Name, query)
 		s = utils.WrapTextByWords(s, 64)
 		return nil, &s
 
@@ -62,9 +72,3 @@ func HandleTextInput(query string) (retItems []globals.Resource, resultStr *stri
 
 	return nil, nil
 }
-
-// UpdateSearchSetting updates the saved search-string.
-func UpdateSearchSetting(s string) {
-	globals.SearchString = s
-	_ = settings.SetSetting("searchstring", s)
-}
@@ -4,101 +4,154 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	g "winfastnav/internal/globals"
-	"winfastnav/internal/utils"
+	"winfastnav/internal/indexcache"
 )
 
 var (
 	DocumentCache   []g.Resource
 	documentCacheMu sync.RWMutex
+	// ready is set once DocumentCache holds a complete index
+	ready atomic.Bool
+
+	// indexing is set while SetupDocs walks the roots, pending when it was asked again meanwhile
+	indexMu           sync.Mutex
+	indexing, pending bool
 )
 
+// Ready reports whether the documents are indexed, false while an indexing is running.
+func Ready() bool {
+	return ready.Load()
+}
+
+// SetupDocs indexes the documents of the configured roots. Calls made while it runs don't
+// start a second walk over the same folders, they make it index once more when done, so a
+// configuration saved meanwhile is picked up.
 func SetupDocs() {
+	indexMu.Lock()
+	if indexing {
+		pending = true
+		indexMu.Unlock()
+		return
+	}
+	indexing = true
+	indexMu.Unlock()
+
+	for {
+		indexDocs()
+		indexMu.Lock()
+		if !pending {
+			indexing = false
+			indexMu.Unlock()
+			return
+		}
+		pending = false
+		indexMu.Unlock()
+	}
+}
+
+func indexDocs() {
 	log.Print("Indexing documents")
+	ready.Store(false)
 	var documentCache []g.Resource
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		log.Printf("failed to get homedir: %v", err)
-		return
+	config := GetIndexConfig()
+	allowed := config.allowedExtensions()
+	excluded := newExclusion(config.Exclude)
+
+	for _, root := range config.Roots {
+		documentCache = append(documentCache, indexRoot(root, allowed, excluded)...)
 	}
 
-	skipIfContains := []string{
-		"\\node_modules\\",
-		"\\venv\\",
-		"\\__pycache__\\",
-		"\\sdk-manifests\\",
-		"\\sdk\\",
+	documentCacheMu.Lock()
+	DocumentCache = documentCache
+	documentCacheMu.Unlock()
+
+	log.Print("Documents indexed")
+	ready.Store(true)
+	if err := indexcache.Save("documents", documentCache); err != nil {
+		log.Printf("Error caching documents: %v", err)
 	}
+}
 
-	relevantExtensions := []string{
-		".doc",
-		".docx",
-		".pdf",
-		".rtf",
-		".odt",
-		".xls",
-		".xlsx",
-		".ppt",
-		".pptx",
+// LoadCachedDocs uses the documents found by the last SetupDocs, and reports false if there are none.
+func LoadCachedDocs() bool {
+	documentCache, ok, err := indexcache.Load("documents")
+	if err != nil {
+		log.Printf("Error reading cached documents: %v", err)
+	}
+	if !ok || err != nil {
+		return false
 	}
+	documentCacheMu.Lock()
+	DocumentCache = documentCache
+	documentCacheMu.Unlock()
+	ready.Store(true)
+	return true
+}
+
+func indexRoot(root IndexRoot, allowed map[string]struct{}, excluded exclusion) []g.Resource {
+	var documents []g.Resource
+	base := filepath.Clean(root.Path)
 
-	err = filepath.Walk(homeDir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
 		if info.IsDir() {
-			if isHiddenDir(info) {
+			if path == base {
+				return nil
+			}
+			if isHiddenDir(info) || excluded.matches(base, path) {
 				return filepath.SkipDir
 			}
-			if utils.ContainsAny(path, skipIfContains) {
+			if root.MaxDepth > 0 && depth(base, path) > root.MaxDepth {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		ext := strings.ToLower(filepath.Ext(path))
-		if !utils.ContainsAny(ext, relevantExtensions) {
+		if _, ok := allowed[strings.ToLower(filepath.Ext(path))]; !ok {
+			return nil
+		}
+		if excluded.matches(base, path) {
 			return nil
 		}
 
-		doc := g.Resource{
+		documents = append(documents, g.Resource{
 			Name:     info.Name(),
 			Filepath: path,
-		}
-		documentCache = append(documentCache, doc)
+		})
 
 		return nil
 	})
 
 	if err != nil {
-		fmt.Printf("Warning: failed to search path %s: %v\n", homeDir, err)
+		fmt.Printf("Warning: failed to search path %s: %v\n", base, err)
 	}
-
-	documentCacheMu.Lock()
-	DocumentCache = documentCache
-	documentCacheMu.Unlock()
-
-	log.Print("Documents indexed")
-	g.FinishedCachingDocs = true
+	return documents
 }
 
-func isHiddenDir(info os.FileInfo) bool {
-	if strings.HasPrefix(info.Name(), ".") {
-		return true
-	}
-
-	if stat, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
-		return stat.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+// depth returns how many directories path is below root.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
 	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
 
-	return false
+// Documents returns a copy of the whole document index.
+func Documents() []g.Resource {
+	documentCacheMu.RLock()
+	defer documentCacheMu.RUnlock()
+	return slices.Clone(DocumentCache)
 }
 
 func FilterDocumentsByName(namePattern string) []g.Resource {
@@ -118,11 +171,3 @@ func FilterDocumentsByName(namePattern string) []g.Resource {
 
 	return filtered
 }
-
-func OpenFile(path string) error {
-	cmd := exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow: true,
-	}
-	return cmd.Start()
-}
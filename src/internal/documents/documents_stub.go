@@ -0,0 +1,10 @@
+//go:build !windows
+
+package documents
+
+import (
+	"os"
+	"strings"
+)
+
+func isHiddenDir(info os.FileInfo) bool { return strings.HasPrefix(info.Name(), ".") }
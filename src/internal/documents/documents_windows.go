@@ -0,0 +1,21 @@
+//go:build windows
+
+package documents
+
+import (
+	"os"
+	"strings"
+	"syscall"
+)
+
+func isHiddenDir(info os.FileInfo) bool {
+	if strings.HasPrefix(info.Name(), ".") {
+		return true
+	}
+
+	if stat, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return stat.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+	}
+
+	return false
+}
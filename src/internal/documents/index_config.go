@@ -0,0 +1,253 @@
+package documents
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"winfastnav/internal/settings"
+)
+
+// IndexRoot is a directory the document indexer walks. MaxDepth limits how many
+// directory levels below Path are visited, 0 means no limit.
+type IndexRoot struct {
+	Path     string `json:"path"`
+	MaxDepth int    `json:"maxDepth,omitempty"`
+}
+
+// IndexConfig describes what SetupDocs indexes. It's stored as JSON under the "documentindex" setting.
+type IndexConfig struct {
+	Roots      []IndexRoot `json:"roots"`
+	Categories []string    `json:"categories"`
+	Extensions []string    `json:"extensions,omitempty"`
+	Exclude    []string    `json:"exclude,omitempty"`
+}
+
+// ExtensionCategories groups the extensions that can be enabled together.
+var ExtensionCategories = map[string][]string{
+	"documents": {".doc", ".docx", ".pdf", ".rtf", ".odt", ".ods", ".odp", ".xls", ".xlsx", ".ppt", ".pptx"},
+	"text":      {".txt", ".md", ".csv", ".log", ".json", ".xml", ".yaml", ".yml", ".ini", ".toml"},
+	"code": {".go", ".py", ".js", ".ts", ".c", ".cpp", ".h", ".hpp", ".cs", ".java", ".kt", ".rs", ".rb", ".php",
+		".sh", ".ps1", ".bat", ".html", ".css", ".sql", ".lua"},
+	"images":   {".png", ".jpg", ".jpeg", ".gif", ".bmp", ".svg", ".webp", ".tif", ".tiff", ".ico"},
+	"archives": {".zip", ".rar", ".7z", ".tar", ".gz", ".bz2", ".xz", ".iso"},
+}
+
+var (
+	indexConfig   IndexConfig
+	indexConfigMu sync.RWMutex
+	loadOnce      sync.Once
+)
+
+// CategoryNames returns the known extension categories in a stable order.
+func CategoryNames() []string {
+	names := make([]string, 0, len(ExtensionCategories))
+	for name := range ExtensionCategories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func defaultIndexConfig() IndexConfig {
+	config := IndexConfig{
+		Categories: []string{"documents"},
+		Exclude:    []string{"node_modules", "venv", "__pycache__", "sdk-manifests", "sdk"},
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		config.Roots = []IndexRoot{{Path: homeDir}}
+	} else {
+		log.Printf("failed to get homedir: %v", err)
+	}
+	return config
+}
+
+// GetIndexConfig returns the current indexing configuration, loading it from settings on first use.
+func GetIndexConfig() IndexConfig {
+	loadOnce.Do(loadIndexConfig)
+	indexConfigMu.RLock()
+	defer indexConfigMu.RUnlock()
+	return indexConfig.clone()
+}
+
+// SetIndexConfig validates and saves the configuration. It does not reindex by itself.
+func SetIndexConfig(config IndexConfig) error {
+	loadOnce.Do(loadIndexConfig)
+	config = config.normalized()
+	for _, pattern := range config.Exclude {
+		if _, err := compileGlob(pattern); err != nil {
+			return err
+		}
+	}
+
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err = settings.SetSetting("documentindex", string(jsonData)); err != nil {
+		return err
+	}
+
+	indexConfigMu.Lock()
+	indexConfig = config
+	indexConfigMu.Unlock()
+	return nil
+}
+
+func loadIndexConfig() {
+	config := defaultIndexConfig()
+	unparsed, err := settings.GetSetting("documentindex")
+	if err == nil && len(unparsed) > 0 {
+		var saved IndexConfig
+		if err = json.Unmarshal([]byte(unparsed), &saved); err != nil {
+			log.Printf("Error parsing document index settings: %v", err)
+		} else {
+			config = saved
+		}
+	}
+
+	indexConfigMu.Lock()
+	indexConfig = config.normalized()
+	indexConfigMu.Unlock()
+}
+
+func (c IndexConfig) clone() IndexConfig {
+	return IndexConfig{
+		Roots:      append([]IndexRoot(nil), c.Roots...),
+		Categories: append([]string(nil), c.Categories...),
+		Extensions: append([]string(nil), c.Extensions...),
+		Exclude:    append([]string(nil), c.Exclude...),
+	}
+}
+
+// normalized trims entries, drops empty ones and makes extensions lowercase with a leading dot.
+func (c IndexConfig) normalized() IndexConfig {
+	var out IndexConfig
+	for _, root := range c.Roots {
+		root.Path = strings.TrimSpace(root.Path)
+		if root.Path == "" {
+			continue
+		}
+		out.Roots = append(out.Roots, IndexRoot{Path: root.Path, MaxDepth: max(root.MaxDepth, 0)})
+	}
+	for _, category := range c.Categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if _, ok := ExtensionCategories[category]; ok {
+			out.Categories = append(out.Categories, category)
+		}
+	}
+	for _, ext := range c.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		out.Extensions = append(out.Extensions, ext)
+	}
+	for _, pattern := range c.Exclude {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			out.Exclude = append(out.Exclude, pattern)
+		}
+	}
+	return out
+}
+
+// allowedExtensions merges the enabled categories with the extra extensions.
+func (c IndexConfig) allowedExtensions() map[string]struct{} {
+	allowed := make(map[string]struct{})
+	for _, category := range c.Categories {
+		for _, ext := range ExtensionCategories[category] {
+			allowed[ext] = struct{}{}
+		}
+	}
+	for _, ext := range c.Extensions {
+		allowed[ext] = struct{}{}
+	}
+	return allowed
+}
+
+// exclusion matches paths against the configured glob patterns.
+//
+// Patterns without a slash are compared with every path element ("node_modules", "*.tmp"),
+// patterns with a slash with the whole path or the path below the index root ("**/build/**").
+// Backslashes are treated as slashes and matching ignores case.
+type exclusion struct {
+	names []*regexp.Regexp
+	paths []*regexp.Regexp
+}
+
+func newExclusion(patterns []string) exclusion {
+	var e exclusion
+	for _, pattern := range patterns {
+		re, err := compileGlob(pattern)
+		if err != nil {
+			log.Printf("Ignoring invalid exclusion %q: %v", pattern, err)
+			continue
+		}
+		if strings.ContainsAny(pattern, `/\`) {
+			e.paths = append(e.paths, re)
+		} else {
+			e.names = append(e.names, re)
+		}
+	}
+	return e
+}
+
+func (e exclusion) matches(root, path string) bool {
+	slashPath := filepath.ToSlash(path)
+	relative := slashPath
+	if rel, err := filepath.Rel(root, path); err == nil {
+		relative = filepath.ToSlash(rel)
+	}
+
+	// only look at elements below the root, so a root inside "sdk" can still be indexed
+	for _, name := range strings.Split(relative, "/") {
+		for _, re := range e.names {
+			if re.MatchString(name) {
+				return true
+			}
+		}
+	}
+	for _, re := range e.paths {
+		if re.MatchString(slashPath) || re.MatchString(relative) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileGlob turns a glob with *, ** and ? into an anchored regular expression.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	runes := []rune(strings.ReplaceAll(strings.TrimSpace(pattern), `\`, "/"))
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				// "**/" may also match no directory at all
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,107 @@
+package documents
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestExclusionMatchesNamesAndPaths(t *testing.T) {
+	excluded := newExclusion([]string{"node_modules", "*.tmp", "**/build/**", `archive\old`})
+	root := filepath.Join("home", "user")
+
+	cases := map[string]bool{
+		filepath.Join(root, "project", "node_modules", "a.pdf"): true,
+		filepath.Join(root, "notes.TMP"):                        true,
+		filepath.Join(root, "app", "build", "out", "r.pdf"):     true,
+		filepath.Join(root, "build", "r.pdf"):                   true,
+		filepath.Join(root, "archive", "old"):                   true,
+		filepath.Join(root, "archive", "new", "r.pdf"):          false,
+		filepath.Join(root, "builds", "r.pdf"):                  false,
+		filepath.Join(root, "report.pdf"):                       false,
+	}
+	for path, want := range cases {
+		if got := excluded.matches(root, path); got != want {
+			t.Errorf("matches(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestExclusionIgnoresElementsAboveRoot(t *testing.T) {
+	excluded := newExclusion([]string{"sdk"})
+	root := filepath.Join("work", "sdk")
+	if excluded.matches(root, filepath.Join(root, "docs", "guide.pdf")) {
+		t.Fatal("root directory name should not exclude its own contents")
+	}
+}
+
+func TestIndexRootHonorsDepthExtensionsAndExclusions(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"top.pdf",
+		"top.go",
+		filepath.Join("one", "nested.docx"),
+		filepath.Join("one", "two", "deep.pdf"),
+		filepath.Join("node_modules", "pkg.pdf"),
+		filepath.Join(".hidden", "secret.pdf"),
+	}
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := IndexConfig{Categories: []string{"documents"}, Exclude: []string{"node_modules"}}.normalized()
+	found := indexRoot(IndexRoot{Path: root, MaxDepth: 1}, config.allowedExtensions(), newExclusion(config.Exclude))
+
+	var names []string
+	for _, doc := range found {
+		names = append(names, doc.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "nested.docx" || names[1] != "top.pdf" {
+		t.Fatalf("unexpected documents: %v", names)
+	}
+}
+
+func TestNormalizedConfig(t *testing.T) {
+	config := IndexConfig{
+		Roots:      []IndexRoot{{Path: "  "}, {Path: " D:\\ ", MaxDepth: -2}},
+		Categories: []string{"Code", "unknown"},
+		Extensions: []string{"EPUB", " .mobi ", ""},
+	}.normalized()
+
+	if len(config.Roots) != 1 || config.Roots[0].Path != "D:\\" || config.Roots[0].MaxDepth != 0 {
+		t.Fatalf("unexpected roots: %+v", config.Roots)
+	}
+	if len(config.Categories) != 1 || config.Categories[0] != "code" {
+		t.Fatalf("unexpected categories: %v", config.Categories)
+	}
+	if len(config.Extensions) != 2 || config.Extensions[0] != ".epub" || config.Extensions[1] != ".mobi" {
+		t.Fatalf("unexpected extensions: %v", config.Extensions)
+	}
+}
+
+func TestSetupDocsWhileIndexingRunsAgainLater(t *testing.T) {
+	indexMu.Lock()
+	indexing = true
+	indexMu.Unlock()
+	t.Cleanup(func() {
+		indexMu.Lock()
+		indexing, pending = false, false
+		indexMu.Unlock()
+	})
+
+	// returns right away instead of walking the roots a second time
+	SetupDocs()
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	if !pending {
+		t.Fatal("the request made during indexing was dropped")
+	}
+}
@@ -0,0 +1,342 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// largest file ReadText will open, whatever the size of the text it holds
+const maxTextSource = 32 << 20
+
+// ErrNoText is returned for files ReadText can't get text out of, such as scanned PDFs.
+var ErrNoText = errors.New("no readable text in this file")
+
+// ReadText extracts the plain text of a .txt-like, .docx or .pdf file. Text beyond limit
+// bytes is cut off, and truncated reports whether that happened.
+func ReadText(path string, limit int) (text string, truncated bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, err
+	}
+	if info.Size() > maxTextSource {
+		return "", false, fmt.Errorf("%s is too large to read", filepath.Base(path))
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case ext == ".docx":
+		text, err = docxText(path)
+	case ext == ".pdf":
+		text, err = pdfText(path)
+	case slices.Contains(ExtensionCategories["text"], ext) || slices.Contains(ExtensionCategories["code"], ext):
+		text, err = plainText(path)
+	default:
+		return "", false, fmt.Errorf("can't read text from %s files", ext)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", false, ErrNoText
+	}
+	if len(text) > limit {
+		// cut at a rune boundary
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		return text[:cut], true, nil
+	}
+	return text, false, nil
+}
+
+func plainText(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return "", ErrNoText
+	}
+	return string(data), nil
+}
+
+// docxText reads the paragraphs of word/document.xml.
+func docxText(path string) (string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	file, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("not a Word document: %w", err)
+	}
+	defer file.Close()
+
+	var b strings.Builder
+	decoder := xml.NewDecoder(file)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteByte('\t')
+			case "br", "cr":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+var (
+	pdfStream = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfFilter = regexp.MustCompile(`/Filter\s*(/\w+|\[[^\]]*\])`)
+)
+
+// pdfText pulls the strings shown by the text operators of each content stream. It handles
+// uncompressed and Flate streams with single-byte fonts, which covers most PDFs saved by
+// office suites, but not scans or CID-keyed fonts.
+func pdfText(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return "", errors.New("not a PDF file")
+	}
+
+	var b strings.Builder
+	for _, match := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := data[match[2]:match[3]]
+		start := match[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := data[start : start+end]
+
+		if filter := pdfFilter.FindSubmatch(dict); filter != nil {
+			if string(filter[1]) != "/FlateDecode" && string(filter[1]) != "[/FlateDecode]" {
+				continue
+			}
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// streams are often followed by padding, so a truncated read still counts
+			content, _ = io.ReadAll(io.LimitReader(reader, maxTextSource))
+			_ = reader.Close()
+		}
+		if text := contentText(content); readable(text) {
+			b.WriteString(text)
+		}
+	}
+	return b.String(), nil
+}
+
+// contentText runs through the operators of a content stream, keeping the text shown by Tj, TJ, ' and ".
+func contentText(content []byte) string {
+	var b strings.Builder
+	var operands []string
+	var array []string
+	inArray := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, next := literalString(content, i)
+			i = next
+			if inArray {
+				array = append(array, s)
+			} else {
+				operands = append(operands, s)
+			}
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return b.String()
+			}
+			s := hexString(content[i+1 : i+end])
+			i += end
+			if inArray {
+				array = append(array, s)
+			} else {
+				operands = append(operands, s)
+			}
+		case c == '[':
+			inArray, array = true, nil
+		case c == ']':
+			inArray = false
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case inArray && (c == '-' || c == '.' || (c >= '0' && c <= '9')):
+			// a big negative adjustment inside TJ is how PDFs usually space words
+			j := i
+			for j < len(content) && (content[j] == '-' || content[j] == '.' || (content[j] >= '0' && content[j] <= '9')) {
+				j++
+			}
+			var n float64
+			if _, err := fmt.Sscan(string(content[i:j]), &n); err == nil && n < -200 {
+				array = append(array, " ")
+			}
+			i = j - 1
+		case isRegular(c):
+			j := i
+			for j < len(content) && isRegular(content[j]) {
+				j++
+			}
+			switch string(content[i:j]) {
+			case "Tj":
+				b.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				b.WriteByte('\n')
+				b.WriteString(strings.Join(operands, ""))
+			case "TJ":
+				b.WriteString(strings.Join(array, ""))
+				array = nil
+			case "T*", "Td", "TD":
+				b.WriteByte('\n')
+			case "ET":
+				b.WriteString("\n")
+			}
+			operands = operands[:0]
+			i = j - 1
+		}
+	}
+	return b.String()
+}
+
+func isRegular(c byte) bool {
+	return c > ' ' && !strings.ContainsRune("()<>[]{}/%", rune(c))
+}
+
+// literalString reads the (string) starting at content[start], returning it and the index of its closing parenthesis.
+func literalString(content []byte, start int) (string, int) {
+	var b []byte
+	depth := 0
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n, j := 0, i
+					for ; j < len(content) && j < i+3 && content[j] >= '0' && content[j] <= '7'; j++ {
+						n = n*8 + int(content[j]-'0')
+					}
+					b = append(b, byte(n))
+					i = j - 1
+				} else {
+					b = append(b, e)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				b = append(b, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return latin1(b), i
+			}
+			b = append(b, c)
+		default:
+			b = append(b, c)
+		}
+	}
+	return latin1(b), len(content)
+}
+
+func hexString(hex []byte) string {
+	var digits []byte
+	for _, c := range hex {
+		if unicode.Is(unicode.ASCII_Hex_Digit, rune(c)) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		_, _ = fmt.Sscanf(string(digits[2*i:2*i+2]), "%02x", &b[i])
+	}
+	return latin1(b)
+}
+
+// latin1 approximates PDFDocEncoding, which matches Latin-1 for the printable characters.
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// readable reports whether text looks like words rather than glyph ids of an embedded font.
+func readable(text string) bool {
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	printable := 0
+	total := 0
+	for _, r := range text {
+		total++
+		if unicode.IsPrint(r) || unicode.IsSpace(r) {
+			printable++
+		}
+	}
+	return printable*10 >= total*9
+}
@@ -0,0 +1,87 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPlainText(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	_ = os.WriteFile(path, []byte("\xef\xbb\xbfhello wörld\n"), 0o600)
+
+	text, truncated, err := ReadText(path, 1000)
+	if err != nil || truncated || text != "hello wörld" {
+		t.Fatalf("got %q %v %v", text, truncated, err)
+	}
+	// the limit falls inside the two bytes of ö
+	text, truncated, _ = ReadText(path, 8)
+	if !truncated || text != "hello w" {
+		t.Errorf("truncated to %q", text)
+	}
+
+	binary := filepath.Join(dir, "data.log")
+	_ = os.WriteFile(binary, []byte{0xff, 0xfe, 0x00}, 0o600)
+	if _, _, err := ReadText(binary, 100); err == nil {
+		t.Error("invalid UTF-8 should be refused")
+	}
+	if _, _, err := ReadText(filepath.Join(dir, "photo.png"), 100); err == nil {
+		t.Error("images have no text")
+	}
+}
+
+func TestReadDocx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "letter.docx")
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, _ := archive.Create("word/document.xml")
+	_, _ = w.Write([]byte(`<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>Dear</w:t></w:r><w:r><w:t xml:space="preserve"> Ana,</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Total:</w:t><w:tab/><w:t>5 &amp; 6</w:t></w:r></w:p></w:body></w:document>`))
+	_ = archive.Close()
+	_ = os.WriteFile(path, buf.Bytes(), 0o600)
+
+	text, _, err := ReadText(path, 1000)
+	if err != nil || text != "Dear Ana,\nTotal:\t5 & 6" {
+		t.Fatalf("got %q %v", text, err)
+	}
+}
+
+func TestReadPDF(t *testing.T) {
+	content := `BT /F1 12 Tf 72 712 Td (Quarterly \(Q3\) report) Tj 0 -14 Td [(Rev)10(enue)-250(grew)] TJ T* <48656c6c6f> Tj ET`
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, _ = zw.Write([]byte(content))
+	_ = zw.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /XObject /Subtype /Image /Filter /DCTDecode /Length 4 >>\nstream\n\xff\xd8\xff\xe0\nendstream\nendobj\n")
+	fmt.Fprintf(&pdf, "2 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	_ = os.WriteFile(path, pdf.Bytes(), 0o600)
+
+	text, _, err := ReadText(path, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Quarterly (Q3) report\nRevenue grew\nHello"; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+
+	scan := filepath.Join(t.TempDir(), "scan.pdf")
+	_ = os.WriteFile(scan, []byte("%PDF-1.4\n%%EOF\n"), 0o600)
+	if _, _, err := ReadText(scan, 1000); err != ErrNoText {
+		t.Errorf("expected ErrNoText, got %v", err)
+	}
+	if strings.Contains(text, "\xff") {
+		t.Error("image data leaked into the text")
+	}
+}
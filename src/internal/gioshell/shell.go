@@ -1,14 +1,22 @@
+// Package gioshell is the developer inspector: a window showing a controller's live state
+// and the commands that led to it.
 package gioshell
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"winfastnav/internal/presentation"
+	"winfastnav/internal/settings"
 )
 
 type Shell struct {
@@ -16,24 +24,30 @@ type Shell struct {
 	window     app.Window
 	ops        op.Ops
 	theme      *material.Theme
+	export     widget.Clickable
+	commands   widget.List
+	// exported is where the last export went, or why it failed
+	exported string
 }
 
 func New(controller *presentation.Controller, title string) *Shell {
 	shell := &Shell{
 		controller: controller,
 		theme:      material.NewTheme(),
+		commands:   widget.List{List: layout.List{Axis: layout.Vertical}},
 	}
 	shell.window.Option(
 		app.Title(title),
-		app.Size(unit.Dp(425), unit.Dp(300)),
+		app.Size(unit.Dp(520), unit.Dp(640)),
 		app.MinSize(unit.Dp(425), unit.Dp(300)),
 	)
-	controller.SetInvalidator(shell.window.Invalidate)
 	return shell
 }
 
+// Run shows the inspector until its window is closed.
 func (s *Shell) Run() error {
-	defer s.controller.SetInvalidator(nil)
+	unsubscribe := s.controller.Subscribe(func(presentation.Change) { s.window.Invalidate() })
+	defer unsubscribe()
 
 	for {
 		switch event := s.window.Event().(type) {
@@ -42,36 +56,87 @@ func (s *Shell) Run() error {
 		case app.FrameEvent:
 			s.ops.Reset()
 			gtx := app.NewContext(&s.ops, event)
+			if s.export.Clicked(gtx) {
+				s.exported = s.exportHistory()
+			}
 			s.layout(gtx)
 			event.Frame(&s.ops)
 		}
 	}
 }
 
+// exportHistory saves the remembered commands next to the settings and describes the outcome.
+func (s *Shell) exportHistory() string {
+	dir, err := settings.Dir()
+	if err != nil {
+		return "Export failed: " + err.Error()
+	}
+	path := filepath.Join(dir, "commands-"+time.Now().Format("20060102-150405")+".json")
+	f, err := os.Create(path)
+	if err != nil {
+		return "Export failed: " + err.Error()
+	}
+	err = s.controller.WriteHistory(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "Export failed: " + err.Error()
+	}
+	return "Saved " + path
+}
+
 func (s *Shell) layout(gtx layout.Context) layout.Dimensions {
 	state := s.controller.Snapshot()
+	entries := s.controller.History().Entries
+	lines := stateLines(state)
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, material.H6(s.theme, "State").Layout),
+				layout.Rigid(material.Button(s.theme, &s.export, "Export").Layout),
+			)
+		}),
+	}
+	for _, line := range lines {
+		children = append(children, layout.Rigid(material.Body2(s.theme, line).Layout))
+	}
+	if s.exported != "" {
+		children = append(children, layout.Rigid(material.Caption(s.theme, s.exported).Layout))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(material.H6(s.theme, fmt.Sprintf("Commands (%d)", len(entries))).Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			// newest first, that's what's being reproduced
+			return material.List(s.theme, &s.commands).Layout(gtx, len(entries), func(gtx layout.Context, i int) layout.Dimensions {
+				entry := entries[len(entries)-1-i]
+				line := fmt.Sprintf("%s  #%d  %s", entry.Time.Format("15:04:05.000"), entry.Batch, entry.Command)
+				return material.Body2(s.theme, line).Layout(gtx)
+			})
+		}),
+	)
 	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-			layout.Rigid(material.H6(s.theme, "winfastnav").Layout),
-			layout.Rigid(material.Body1(s.theme, pageLabel(state.Page)).Layout),
-			layout.Rigid(material.Body1(s.theme, fmt.Sprintf("Mode: %d", state.Mode)).Layout),
-			layout.Rigid(material.Body1(s.theme, fmt.Sprintf("Query: %s", state.Query)).Layout),
-			layout.Rigid(material.Body1(s.theme, state.Message).Layout),
-		)
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
 	})
 }
 
-func pageLabel(page presentation.Page) string {
-	switch page {
-	case presentation.PageMenu:
-		return "Menu"
-	case presentation.PageHelp:
-		return "Help"
-	case presentation.PageSettings:
-		return "Settings"
-	case presentation.PageAbout:
-		return "About"
-	default:
-		return "Launcher"
+// stateLines describes every field of the state, one per line.
+func stateLines(state presentation.State) []string {
+	titles := make([]string, len(state.Results))
+	for i, r := range state.Results {
+		titles[i] = r.Title
+	}
+	return []string{
+		fmt.Sprintf("Visible: %t", state.Visible),
+		fmt.Sprintf("Mode: %d", state.Mode),
+		fmt.Sprintf("Page: %s", state.Page),
+		fmt.Sprintf("Query: %q", state.Query),
+		fmt.Sprintf("Message: %q", state.Message),
+		fmt.Sprintf("Loading: %t", state.Loading),
+		fmt.Sprintf("Focus search: %t", state.FocusSearch),
+		fmt.Sprintf("Dialog: %s", state.Dialog.Kind),
+		fmt.Sprintf("Selected: %d", state.Selected),
+		fmt.Sprintf("Results (%d): %s", len(titles), strings.Join(titles, ", ")),
 	}
 }
@@ -7,6 +7,21 @@ import (
 type Resource struct {
 	Name     string
 	Filepath string
+	// Publisher is who made an installed app, when Windows knows it.
+	Publisher string `json:",omitempty"`
+}
+
+// BlockRule hides the installed apps it matches. Kind is one of the Block* constants.
+type BlockRule struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+}
+
+type SearchEngine struct {
+	Name    string `json:"name"`
+	Keyword string `json:"keyword"`
+	URL     string `json:"url"`
+	Default bool   `json:"default,omitempty"`
 }
 
 const (
@@ -17,17 +32,22 @@ const (
 	ModeChooseProgram = 21
 
 	ModeAskGPT = 31
+
+	ModeCommand = 41
+)
+
+const (
+	BlockPath  = "path"
+	BlockGlob  = "glob"
+	BlockRegex = "regex"
+	BlockName  = "name"
 )
 
 var (
 	AppName       = "winfastnav v0.5"
 	AppList       []Resource
-	ExecBlocklist []string
-	SearchString  string
-
-	FinishedCachingDocs = false
-
-	CurrentMode int = ModeSearchProgram
+	ExecBlocklist []BlockRule
+	SearchEngines []SearchEngine
 
 	//go:embed assets/icon.ico
 	IconBytes []byte
@@ -0,0 +1,64 @@
+package hotkey
+
+import (
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+	"winfastnav/internal/settings"
+)
+
+// DefaultBindings is used until the user saves their own.
+var DefaultBindings = []Binding{{Keys: "Alt+O", Action: ActionShowPrograms}}
+
+var (
+	// bindings caches the saved hotkeys, the settings pages read them every frame
+	bindings   []Binding
+	bindingsMu sync.RWMutex
+	loadOnce   sync.Once
+)
+
+// Bindings returns the configured hotkeys, stored as JSON under the "hotkeys" setting.
+func Bindings() []Binding {
+	loadOnce.Do(loadBindings)
+	bindingsMu.RLock()
+	defer bindingsMu.RUnlock()
+	return slices.Clone(bindings)
+}
+
+func loadBindings() {
+	saved := append([]Binding(nil), DefaultBindings...)
+	unparsed, err := settings.GetSetting("hotkeys")
+	if err == nil && len(unparsed) > 0 {
+		var parsed []Binding
+		if err = json.Unmarshal([]byte(unparsed), &parsed); err != nil {
+			log.Printf("Error parsing hotkeys: %v", err)
+		} else {
+			saved = parsed
+		}
+	}
+
+	bindingsMu.Lock()
+	bindings = saved
+	bindingsMu.Unlock()
+}
+
+// SetBindings validates and saves the hotkeys. They take effect the next time Start is called.
+func SetBindings(saved []Binding) error {
+	loadOnce.Do(loadBindings)
+	if err := Validate(saved); err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err = settings.SetSetting("hotkeys", string(jsonData)); err != nil {
+		return err
+	}
+	bindingsMu.Lock()
+	bindings = slices.Clone(saved)
+	bindingsMu.Unlock()
+	return nil
+}
@@ -4,5 +4,5 @@ package hotkey
 
 type Listener struct{}
 
-func Start(func()) (*Listener, error) { return &Listener{}, nil }
-func (*Listener) Stop()               {}
+func Start([]Binding, func(Action)) (*Listener, error) { return &Listener{}, nil }
+func (*Listener) Stop()                                {}
@@ -3,6 +3,7 @@
 package hotkey
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 	"sync"
@@ -12,11 +13,10 @@ import (
 )
 
 const (
-	modifierAlt = 0x0001
-	virtualKeyO = 0x4f
-	hotkeyID    = 1
-	wmHotkey    = 0x0312
-	wmQuit      = 0x0012
+	modifierNoRepeat             = 0x4000
+	errorHotkeyAlreadyRegistered = windows.Errno(1409)
+	wmHotkey                     = 0x0312
+	wmQuit                       = 0x0012
 )
 
 var (
@@ -44,18 +44,27 @@ type message struct {
 }
 
 type Listener struct {
-	mu       sync.Mutex
-	threadID uint32
+	mu         sync.Mutex
+	threadID   uint32
+	registered int
+	done       chan struct{}
 }
 
-func Start(onHotkey func()) (*Listener, error) {
-	listener := &Listener{}
+// Start registers every binding and calls onHotkey with the binding's action when it's pressed.
+// Bindings that can't be registered are reported as *BindingError values joined in the returned
+// error, the listener keeps running for the rest. It is nil only if no binding could be registered.
+func Start(bindings []Binding, onHotkey func(Action)) (*Listener, error) {
+	listener := &Listener{done: make(chan struct{})}
 	ready := make(chan error, 1)
-	go listener.run(onHotkey, ready)
-	if err := <-ready; err != nil {
+	go listener.run(bindings, onHotkey, ready)
+	err := <-ready
+	if listener.registered == 0 {
+		if err == nil {
+			err = errors.New("no hotkeys configured")
+		}
 		return nil, err
 	}
-	return listener, nil
+	return listener, err
 }
 
 func (l *Listener) Stop() {
@@ -65,24 +74,46 @@ func (l *Listener) Stop() {
 	if threadID != 0 {
 		procPostThreadMessage.Call(uintptr(threadID), wmQuit, 0, 0)
 	}
+	// wait for the hotkeys to be unregistered, so they can be registered again right away
+	<-l.done
 }
 
-func (l *Listener) run(onHotkey func(), ready chan<- error) {
+func (l *Listener) run(bindings []Binding, onHotkey func(Action), ready chan<- error) {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
+	defer close(l.done)
 
 	threadID, _, _ := procGetCurrentThreadID.Call()
 	l.mu.Lock()
 	l.threadID = uint32(threadID)
 	l.mu.Unlock()
 
-	registered, _, err := procRegisterHotKey.Call(0, hotkeyID, modifierAlt, virtualKeyO)
-	if registered == 0 {
-		ready <- fmt.Errorf("register Alt+O hotkey: %w", err)
+	// hotkey ids are the binding index + 1, RegisterHotKey wants them non-zero
+	actions := make(map[uintptr]Action)
+	var errs []error
+	for i, binding := range bindings {
+		combination, err := Parse(binding.Keys)
+		if err != nil {
+			errs = append(errs, &BindingError{Binding: binding, Err: err})
+			continue
+		}
+		id := uintptr(i + 1)
+		registered, _, err := procRegisterHotKey.Call(0, id, uintptr(combination.Modifiers)|modifierNoRepeat, uintptr(combination.Key))
+		if registered == 0 {
+			if errors.Is(err, errorHotkeyAlreadyRegistered) {
+				err = ErrTaken
+			}
+			errs = append(errs, &BindingError{Binding: binding, Err: fmt.Errorf("register %s: %w", combination, err)})
+			continue
+		}
+		actions[id] = binding.Action
+		defer procUnregisterHotKey.Call(0, id)
+	}
+	l.registered = len(actions)
+	ready <- errors.Join(errs...)
+	if len(actions) == 0 {
 		return
 	}
-	defer procUnregisterHotKey.Call(0, hotkeyID)
-	ready <- nil
 
 	for {
 		var message message
@@ -91,7 +122,9 @@ func (l *Listener) run(onHotkey func(), ready chan<- error) {
 			return
 		}
 		if message.Message == wmHotkey {
-			onHotkey()
+			if action, ok := actions[message.WParam]; ok {
+				onHotkey(action)
+			}
 		}
 	}
 }
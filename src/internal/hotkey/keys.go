@@ -0,0 +1,204 @@
+package hotkey
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Modifier values match the Win32 MOD_* flags so they can be passed to RegisterHotKey as-is.
+type Modifier uint32
+
+const (
+	ModAlt   Modifier = 0x0001
+	ModCtrl  Modifier = 0x0002
+	ModShift Modifier = 0x0004
+	ModWin   Modifier = 0x0008
+)
+
+// Action is what a hotkey does when pressed.
+type Action string
+
+const (
+	ActionShowPrograms  Action = "programs"
+	ActionShowDocuments Action = "documents"
+	ActionShowWindows   Action = "windows"
+	ActionShowGPT       Action = "gpt"
+)
+
+// Actions lists every action a binding can have, in the order the settings page cycles through them.
+var Actions = []Action{ActionShowPrograms, ActionShowDocuments, ActionShowWindows, ActionShowGPT}
+
+// Binding maps a key combination such as "Ctrl+Shift+Space" to an action.
+type Binding struct {
+	Keys   string `json:"keys"`
+	Action Action `json:"action"`
+}
+
+// Combination is a parsed key combination. Key is a Windows virtual-key code.
+type Combination struct {
+	Modifiers Modifier
+	Key       uint32
+}
+
+var modifierNames = map[string]Modifier{
+	"ctrl":    ModCtrl,
+	"control": ModCtrl,
+	"alt":     ModAlt,
+	"shift":   ModShift,
+	"win":     ModWin,
+	"super":   ModWin,
+	"meta":    ModWin,
+}
+
+var keyNames = map[string]uint32{
+	"space": 0x20, "enter": 0x0d, "return": 0x0d, "tab": 0x09, "esc": 0x1b, "escape": 0x1b,
+	"backspace": 0x08, "delete": 0x2e, "del": 0x2e, "insert": 0x2d, "ins": 0x2d,
+	"home": 0x24, "end": 0x23, "pageup": 0x21, "pgup": 0x21, "pagedown": 0x22, "pgdn": 0x22,
+	"left": 0x25, "up": 0x26, "right": 0x27, "down": 0x28,
+	"`": 0xc0, "-": 0xbd, "=": 0xbb, "plus": 0xbb, "[": 0xdb, "]": 0xdd, "\\": 0xdc,
+	";": 0xba, "'": 0xde, ",": 0xbc, ".": 0xbe, "/": 0xbf,
+}
+
+// display names used by Combination.String, for the keys that aren't a letter, digit or F-key
+var keyLabels = map[uint32]string{
+	0x20: "Space", 0x0d: "Enter", 0x09: "Tab", 0x1b: "Esc", 0x08: "Backspace", 0x2e: "Delete", 0x2d: "Insert",
+	0x24: "Home", 0x23: "End", 0x21: "PageUp", 0x22: "PageDown",
+	0x25: "Left", 0x26: "Up", 0x27: "Right", 0x28: "Down",
+	0xc0: "`", 0xbd: "-", 0xbb: "=", 0xdb: "[", 0xdd: "]", 0xdc: "\\", 0xba: ";", 0xde: "'", 0xbc: ",", 0xbe: ".", 0xbf: "/",
+}
+
+// Parse reads a combination such as "Ctrl+Shift+Space" or "alt + o".
+// It needs at least one modifier and exactly one key.
+func Parse(s string) (Combination, error) {
+	var combination Combination
+	if strings.TrimSpace(s) == "" {
+		return combination, errors.New("empty key combination")
+	}
+
+	hasKey := false
+	for _, part := range strings.Split(s, "+") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			return combination, fmt.Errorf("%q: missing key between '+' signs, use \"Plus\" for the + key", s)
+		}
+
+		if modifier, ok := modifierNames[name]; ok {
+			if combination.Modifiers&modifier != 0 {
+				return combination, fmt.Errorf("%q: %s is repeated", s, part)
+			}
+			combination.Modifiers |= modifier
+			continue
+		}
+
+		key, ok := parseKey(name)
+		if !ok {
+			return combination, fmt.Errorf("%q: unknown key %q", s, strings.TrimSpace(part))
+		}
+		if hasKey {
+			return combination, fmt.Errorf("%q: only one non-modifier key is allowed", s)
+		}
+		combination.Key = key
+		hasKey = true
+	}
+
+	if !hasKey {
+		return combination, fmt.Errorf("%q: missing a key after the modifiers", s)
+	}
+	if combination.Modifiers == 0 {
+		return combination, fmt.Errorf("%q: needs at least one of Ctrl, Alt, Shift or Win", s)
+	}
+	return combination, nil
+}
+
+func parseKey(name string) (uint32, bool) {
+	if key, ok := keyNames[name]; ok {
+		return key, true
+	}
+	if len(name) == 1 {
+		c := name[0]
+		if c >= 'a' && c <= 'z' {
+			return uint32(c - 'a' + 'A'), true
+		}
+		if c >= '0' && c <= '9' {
+			return uint32(c), true
+		}
+	}
+	var n int
+	if _, err := fmt.Sscanf(name, "f%d", &n); err == nil && fmt.Sprintf("f%d", n) == name && n >= 1 && n <= 24 {
+		return uint32(0x70 + n - 1), true
+	}
+	if _, err := fmt.Sscanf(name, "num%d", &n); err == nil && fmt.Sprintf("num%d", n) == name && n >= 0 && n <= 9 {
+		return uint32(0x60 + n), true
+	}
+	return 0, false
+}
+
+// String formats the combination the way Parse reads it, with modifiers in a fixed order.
+func (c Combination) String() string {
+	var parts []string
+	if c.Modifiers&ModCtrl != 0 {
+		parts = append(parts, "Ctrl")
+	}
+	if c.Modifiers&ModAlt != 0 {
+		parts = append(parts, "Alt")
+	}
+	if c.Modifiers&ModShift != 0 {
+		parts = append(parts, "Shift")
+	}
+	if c.Modifiers&ModWin != 0 {
+		parts = append(parts, "Win")
+	}
+
+	switch {
+	case keyLabels[c.Key] != "":
+		parts = append(parts, keyLabels[c.Key])
+	case c.Key >= 'A' && c.Key <= 'Z', c.Key >= '0' && c.Key <= '9':
+		parts = append(parts, string(rune(c.Key)))
+	case c.Key >= 0x70 && c.Key <= 0x87:
+		parts = append(parts, fmt.Sprintf("F%d", c.Key-0x70+1))
+	case c.Key >= 0x60 && c.Key <= 0x69:
+		parts = append(parts, fmt.Sprintf("Num%d", c.Key-0x60))
+	default:
+		parts = append(parts, fmt.Sprintf("0x%02x", c.Key))
+	}
+	return strings.Join(parts, "+")
+}
+
+// Validate checks that every binding parses, has a known action and that no combination is used twice.
+func Validate(bindings []Binding) error {
+	var errs []error
+	seen := make(map[Combination]string)
+	for _, binding := range bindings {
+		combination, err := Parse(binding.Keys)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !slices.Contains(Actions, binding.Action) {
+			errs = append(errs, fmt.Errorf("%s: unknown action %q", combination, binding.Action))
+		}
+		if previous, ok := seen[combination]; ok {
+			errs = append(errs, fmt.Errorf("%s is bound twice (%q and %q)", combination, previous, binding.Keys))
+			continue
+		}
+		seen[combination] = binding.Keys
+	}
+	return errors.Join(errs...)
+}
+
+// ErrTaken is wrapped by BindingError when another application already owns the combination.
+var ErrTaken = errors.New("already registered by another application")
+
+// BindingError reports a binding that couldn't be registered.
+type BindingError struct {
+	Binding Binding
+	Err     error
+}
+
+func (e *BindingError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Binding.Keys, e.Binding.Action, e.Err)
+}
+
+func (e *BindingError) Unwrap() error { return e.Err }
@@ -0,0 +1,84 @@
+package hotkey
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCombinations(t *testing.T) {
+	cases := map[string]Combination{
+		"Alt+O":                {Modifiers: ModAlt, Key: 'O'},
+		"ctrl + shift + space": {Modifiers: ModCtrl | ModShift, Key: 0x20},
+		"Win+F12":              {Modifiers: ModWin, Key: 0x7b},
+		"Control+Alt+7":        {Modifiers: ModCtrl | ModAlt, Key: '7'},
+		"Super+Num0":           {Modifiers: ModWin, Key: 0x60},
+		"Shift+Alt+/":          {Modifiers: ModShift | ModAlt, Key: 0xbf},
+		"Ctrl+Plus":            {Modifiers: ModCtrl, Key: 0xbb},
+	}
+	for input, want := range cases {
+		got, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Parse(%q) = %+v, want %+v", input, got, want)
+		}
+	}
+}
+
+func TestParseRejectsInvalidCombinations(t *testing.T) {
+	for _, input := range []string{"", "O", "Alt", "Alt+", "Alt++O", "Alt+O+P", "Alt+Alt+O", "Alt+F25", "Hyper+O", "Ctrl+F1x"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) should fail", input)
+		}
+	}
+}
+
+func TestCombinationStringRoundTrips(t *testing.T) {
+	for _, input := range []string{"Alt+O", "Ctrl+Shift+Space", "Ctrl+Alt+Shift+Win+F1", "Win+Num5", "Alt+`", "Ctrl+PageDown"} {
+		combination, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", input, err)
+		}
+		if combination.String() != input {
+			t.Errorf("String() = %q, want %q", combination.String(), input)
+		}
+		again, err := Parse(combination.String())
+		if err != nil || again != combination {
+			t.Errorf("%q did not round trip: %+v %v", input, again, err)
+		}
+	}
+}
+
+func TestValidateBindings(t *testing.T) {
+	valid := []Binding{
+		{Keys: "Alt+O", Action: ActionShowPrograms},
+		{Keys: "Ctrl+Shift+Space", Action: ActionShowWindows},
+		{Keys: "Alt+G", Action: ActionShowGPT},
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := []Binding{
+		{Keys: "Alt+O", Action: ActionShowPrograms},
+		{Keys: "alt + o", Action: ActionShowGPT},
+		{Keys: "Alt+P", Action: "launch-rockets"},
+		{Keys: "P", Action: ActionShowPrograms},
+	}
+	err := Validate(invalid)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	if count := len(err.(interface{ Unwrap() []error }).Unwrap()); count != 3 {
+		t.Fatalf("expected 3 problems, got %d: %v", count, err)
+	}
+}
+
+func TestBindingErrorUnwrapsTaken(t *testing.T) {
+	err := error(&BindingError{Binding: Binding{Keys: "Alt+O"}, Err: ErrTaken})
+	if !errors.Is(err, ErrTaken) {
+		t.Fatal("BindingError should unwrap to ErrTaken")
+	}
+}
@@ -0,0 +1,59 @@
+package indexcache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/settings"
+)
+
+// dir is where the indexes are written, a "cache" folder next to the settings file.
+var dir = func() (string, error) {
+	base, err := settings.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "cache"), nil
+}
+
+// Save writes the latest index so command-line queries don't have to walk the disk again.
+func Save(name string, items []g.Resource) error {
+	cacheDir, err := dir()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(cacheDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	// write and rename, so a reader never sees half an index
+	tmp := filepath.Join(cacheDir, name+".json.tmp")
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(cacheDir, name+".json"))
+}
+
+// Load reads an index written by Save. It reports false when there is none yet.
+func Load(name string) ([]g.Resource, bool, error) {
+	cacheDir, err := dir()
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(filepath.Join(cacheDir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var items []g.Resource
+	if err = json.Unmarshal(data, &items); err != nil {
+		return nil, false, err
+	}
+	return items, true, nil
+}
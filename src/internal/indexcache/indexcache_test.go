@@ -0,0 +1,25 @@
+package indexcache
+
+import (
+	"slices"
+	"testing"
+	g "winfastnav/internal/globals"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	tmp := t.TempDir()
+	dir = func() (string, error) { return tmp, nil }
+
+	if _, ok, err := Load("apps"); ok || err != nil {
+		t.Fatalf("empty cache should load nothing, got %v %v", ok, err)
+	}
+
+	items := []g.Resource{{Name: "Editor", Filepath: "/usr/bin/editor"}, {Name: "Notes", Filepath: "/home/u/notes.txt"}}
+	if err := Save("apps", items); err != nil {
+		t.Fatal(err)
+	}
+	loaded, ok, err := Load("apps")
+	if err != nil || !ok || !slices.Equal(loaded, items) {
+		t.Fatalf("got %v %v %v", loaded, ok, err)
+	}
+}
@@ -0,0 +1,191 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+	g "winfastnav/internal/globals"
+)
+
+// Commands understood by the running instance.
+const (
+	CommandShow     = "show"
+	CommandHide     = "hide"
+	CommandSetMode  = "set-mode"
+	CommandSetQuery = "set-query"
+	CommandReindex  = "reindex"
+	CommandQuit     = "quit"
+	CommandInspect  = "inspect"
+)
+
+// Modes maps the names accepted by set-mode to launcher modes.
+var Modes = map[string]int{
+	"programs":  g.ModeSearchProgram,
+	"documents": g.ModeSearchDocument,
+	"internet":  g.ModeSearchInternet,
+	"windows":   g.ModeChooseProgram,
+	"gpt":       g.ModeAskGPT,
+	"command":   g.ModeCommand,
+}
+
+// Request is one line of JSON sent to the running instance. Mode is used by set-mode,
+// and by set-query to switch mode before typing Query.
+type Request struct {
+	Command string `json:"command"`
+	Mode    string `json:"mode,omitempty"`
+	Query   string `json:"query,omitempty"`
+}
+
+type response struct {
+	Error string `json:"error,omitempty"`
+}
+
+var (
+	// ErrRunning is returned by Listen when another instance already owns the channel.
+	ErrRunning = errors.New("winfastnav is already running")
+	// ErrNotRunning is returned by Send when there's no instance to talk to.
+	ErrNotRunning = errors.New("winfastnav is not running")
+)
+
+// Validate checks the command and its arguments.
+func (r Request) Validate() error {
+	switch r.Command {
+	case CommandShow, CommandHide, CommandReindex, CommandQuit, CommandInspect:
+		return nil
+	case CommandSetMode:
+		if r.Mode == "" {
+			return errors.New("set-mode needs a mode")
+		}
+	case CommandSetQuery:
+	default:
+		return fmt.Errorf("unknown command %q", r.Command)
+	}
+	if _, ok := Modes[r.Mode]; r.Mode != "" && !ok {
+		return fmt.Errorf("unknown mode %q", r.Mode)
+	}
+	return nil
+}
+
+// Handler carries out a request in the running instance.
+type Handler func(Request) error
+
+// Server is the running instance's end of the channel.
+type Server struct {
+	listener net.Listener
+	handler  Handler
+	wg       sync.WaitGroup
+}
+
+// Listen claims the channel for this instance and serves requests with handler until Close.
+func Listen(handler Handler) (*Server, error) {
+	if conn, err := dial(); err == nil {
+		_ = conn.Close()
+		return nil, ErrRunning
+	}
+	listener, err := listen()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: listener, handler: handler}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("IPC accept failed: %v", err)
+			}
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle answers every request on the connection in order, one JSON line each.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+		if !scanner.Scan() {
+			return
+		}
+		var req Request
+		err := json.Unmarshal(scanner.Bytes(), &req)
+		if err == nil {
+			err = req.Validate()
+		}
+		if err == nil && req.Command == CommandQuit {
+			// quitting ends the process, so it is acknowledged before it is carried out
+			if encoder.Encode(response{}) == nil {
+				_ = s.handler(req)
+			}
+			return
+		}
+		if err == nil {
+			err = s.handler(req)
+		}
+		var resp response
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if encoder.Encode(resp) != nil {
+			return
+		}
+	}
+}
+
+// Close stops accepting requests and releases the channel.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// Send delivers requests to the running instance in order and returns the first error it reports.
+func Send(requests ...Request) error {
+	conn, err := dial()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	encoder := json.NewEncoder(conn)
+	scanner := bufio.NewScanner(conn)
+	for _, req := range requests {
+		if err = encoder.Encode(req); err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			if scanner.Err() != nil {
+				return scanner.Err()
+			}
+			// quit may close the connection before answering
+			if req.Command == CommandQuit {
+				return nil
+			}
+			return errors.New("no response from the running instance")
+		}
+		var resp response
+		if err = json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("%s: %s", req.Command, resp.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// socketPath prefers the per-user runtime directory, which only the user can read.
+func socketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "winfastnav.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("winfastnav-%d.sock", os.Getuid()))
+}
+
+// listen takes a lock next to the socket first. Whoever holds it owns the socket, so one left
+// by a crash can be removed without taking it from an instance starting at the same time.
+func listen() (net.Listener, error) {
+	path := socketPath()
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = lock.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrRunning
+		}
+		return nil, err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		_ = lock.Close()
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err == nil {
+		err = os.Chmod(path, 0o600)
+		if err != nil {
+			_ = listener.Close()
+		}
+	}
+	if err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	return lockedListener{Listener: listener, lock: lock}, nil
+}
+
+// lockedListener gives up the lock when it stops listening.
+type lockedListener struct {
+	net.Listener
+	lock *os.File
+}
+
+func (l lockedListener) Close() error {
+	err := l.Listener.Close()
+	if closeErr := l.lock.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func dial() (net.Conn, error) {
+	return net.DialTimeout("unix", socketPath(), 2*time.Second)
+}
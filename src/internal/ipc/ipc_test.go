@@ -0,0 +1,115 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSecondInstanceForwardsRequests(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	var mu sync.Mutex
+	var received []Request
+	server, err := Listen(func(req Request) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, req)
+		if req.Command == CommandReindex {
+			return errors.New("already indexing")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if _, err := Listen(func(Request) error { return nil }); !errors.Is(err, ErrRunning) {
+		t.Fatalf("second Listen should report ErrRunning, got %v", err)
+	}
+
+	sent := []Request{{Command: CommandSetMode, Mode: "documents"}, {Command: CommandSetQuery, Query: "report"}}
+	if err := Send(sent...); err != nil {
+		t.Fatal(err)
+	}
+	if err := Send(Request{Command: CommandReindex}); err == nil || err.Error() != "reindex: already indexing" {
+		t.Fatalf("handler error should reach the sender, got %v", err)
+	}
+	if err := Send(Request{Command: CommandSetMode, Mode: "music"}); err == nil {
+		t.Fatal("invalid request should be rejected")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(received, append(sent, Request{Command: CommandReindex})) {
+		t.Fatalf("received %+v", received)
+	}
+}
+
+func TestSendWithoutServer(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	if err := Send(Request{Command: CommandShow}); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	if err := os.WriteFile(socketPath(), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server, err := Listen(func(Request) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	if err := Send(Request{Command: CommandShow}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimultaneousStartKeepsFirstSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	first, err := listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	// the second instance also failed to dial, before the first one listened
+	if second, err := listen(); !errors.Is(err, ErrRunning) {
+		if second != nil {
+			second.Close()
+		}
+		t.Fatalf("second listen returned %v", err)
+	}
+	if _, err := os.Stat(socketPath()); err != nil {
+		t.Fatalf("first instance lost its socket: %v", err)
+	}
+}
+
+func TestQuitIsAnsweredBeforeItRuns(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	quitting := make(chan struct{})
+	exit := make(chan struct{})
+	defer close(exit)
+	server, err := Listen(func(req Request) error {
+		close(quitting)
+		// the process would end here
+		<-exit
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if err := Send(Request{Command: CommandQuit}); err != nil {
+		t.Fatal(err)
+	}
+	<-quitting
+}
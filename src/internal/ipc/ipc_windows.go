@@ -0,0 +1,32 @@
+//go:build windows
+
+package ipc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+	"golang.org/x/sys/windows"
+)
+
+// one pipe per user, so instances in different sessions don't see each other
+func pipeName() string {
+	return `\\.\pipe\winfastnav-` + os.Getenv("USERNAME")
+}
+
+func listen() (net.Listener, error) {
+	listener, err := winio.ListenPipe(pipeName(), nil)
+	// the first pipe instance flag makes a second server fail even when the race beat dial
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) || errors.Is(err, windows.ERROR_PIPE_BUSY) {
+		return nil, ErrRunning
+	}
+	return listener, err
+}
+
+func dial() (net.Conn, error) {
+	timeout := 2 * time.Second
+	return winio.DialPipe(pipeName(), &timeout)
+}
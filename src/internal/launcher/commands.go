@@ -0,0 +1,102 @@
+package launcher
+
+import (
+	"context"
+
+	"winfastnav/internal/agent"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/search"
+)
+
+// route asks the model what request means. Actions wait for confirmation, answers are just shown.
+func (l *Launcher) route(request string) {
+	l.cancelRoute()
+	ctx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	l.routeRequest++
+	id := l.routeRequest
+	l.routeCancel = cancel
+	l.mu.Unlock()
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandCloseDialog})
+
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: true})
+	l.Message("Working out what to do...")
+	go func() {
+		defer cancel()
+		result, err := l.env.Route(ctx, request)
+
+		l.updateMu.Lock()
+		defer l.updateMu.Unlock()
+		l.mu.Lock()
+		current := l.routeRequest == id
+		if current {
+			l.routeCancel = nil
+		}
+		l.mu.Unlock()
+		if !current {
+			return
+		}
+		l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: false})
+		switch {
+		case err != nil:
+			l.Message("Command error: " + err.Error())
+		case result.Action != nil:
+			l.controller.Dispatch(presentation.Command{Kind: presentation.CommandOpenDialog, Dialog: presentation.Dialog{Kind: presentation.DialogRunAction, Payload: *result.Action}})
+			l.Message(result.Action.Describe() + "\n\nPress Enter to confirm or Escape to cancel.")
+		default:
+			l.Message(result.Answer)
+		}
+	}()
+}
+
+// cancelRoute stops the request being worked out or drops the action waiting for
+// confirmation, reporting false if there was neither.
+func (l *Launcher) cancelRoute() bool {
+	l.updateMu.Lock()
+	defer l.updateMu.Unlock()
+	_, pending := l.TakeDialog(presentation.DialogRunAction)
+	l.mu.Lock()
+	cancel := l.routeCancel
+	l.routeCancel = nil
+	if cancel != nil {
+		l.routeRequest++
+	}
+	l.mu.Unlock()
+	if cancel == nil && !pending {
+		return false
+	}
+	if cancel != nil {
+		cancel()
+		l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: false})
+	}
+	l.Message("")
+	return true
+}
+
+// confirmAction runs the action the user was asked about.
+func (l *Launcher) confirmAction() {
+	dialog, ok := l.TakeDialog(presentation.DialogRunAction)
+	if !ok {
+		return
+	}
+	action := dialog.Payload.(agent.Action)
+
+	var err error
+	switch action.Kind {
+	case agent.ActionOpenApp:
+		err = l.env.Opener.OpenProgram(action.Target)
+	case agent.ActionOpenDocument:
+		err = l.env.Opener.OpenFile(action.Target)
+	case agent.ActionFocusWindow:
+		l.focusWindow(action.Window)
+		return
+	case agent.ActionWebSearch:
+		l.webSearch(search.URL(search.Default(), action.Target))
+		return
+	}
+	if err != nil {
+		l.Message("Sorry, that didn't work: " + err.Error())
+		return
+	}
+	l.Hide()
+}
@@ -0,0 +1,289 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"winfastnav/internal/chat"
+	"winfastnav/internal/clipboard"
+	"winfastnav/internal/documents"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/llm"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/prompts"
+)
+
+// askGPT adds prompt to the current chat and streams the answer into the message,
+// replacing any answer still in progress.
+func (l *Launcher) askGPT(prompt string) {
+	l.cancelGPT()
+	ctx, cancel := context.WithCancel(context.Background())
+	// a typed prompt replaces one waiting to be sent
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandCloseDialog})
+	l.mu.Lock()
+	if l.chat == nil {
+		l.chat = chat.New()
+	}
+	l.chat.Add(llm.RoleUser, prompt)
+	messages := slices.Clone(l.chat.Messages)
+	l.gptRequest++
+	request := l.gptRequest
+	l.gptCancel = cancel
+	l.mu.Unlock()
+
+	// the transcript takes the place of a chat list
+	l.controller.Batch(
+		presentation.Command{Kind: presentation.CommandSetResults},
+		presentation.Command{Kind: presentation.CommandSetLoading, Loading: true},
+		message("Please wait..."),
+	)
+	go func() {
+		defer cancel()
+		_, err := llm.Stream(ctx, l.env.LLM(), messages, func(token string) {
+			l.updateMu.Lock()
+			defer l.updateMu.Unlock()
+			// a cancelled request may still deliver the token it was reading
+			if !l.currentGPT(request) {
+				return
+			}
+			command := presentation.Command{Kind: presentation.CommandAppendMessage, Message: token}
+			if l.gptAnswer.Len() == 0 {
+				command = presentation.Command{Kind: presentation.CommandSetMessage, Message: "GPT: " + token}
+			}
+			l.gptAnswer.WriteString(token)
+			l.controller.Post(command)
+		})
+
+		l.updateMu.Lock()
+		defer l.updateMu.Unlock()
+		l.mu.Lock()
+		current := l.gptRequest == request
+		if current {
+			l.gptCancel = nil
+		}
+		l.mu.Unlock()
+		if !current {
+			return
+		}
+		l.finishAnswer("")
+		if err != nil {
+			l.Message("Quick GPT error: " + err.Error())
+		}
+	}()
+}
+
+func (l *Launcher) currentGPT(request int) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.gptRequest == request
+}
+
+// cancelGPT stops the answer in progress, keeping what arrived so far. It reports false if there was none.
+func (l *Launcher) cancelGPT() bool {
+	l.updateMu.Lock()
+	defer l.updateMu.Unlock()
+	l.mu.Lock()
+	cancel := l.gptCancel
+	l.gptCancel = nil
+	if cancel != nil {
+		l.gptRequest++
+	}
+	l.mu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	l.finishAnswer(" [stopped]")
+	return true
+}
+
+// finishAnswer moves the streamed answer from the message into the chat and saves it. Without
+// any answer the question is taken back, so it isn't sent again with the next one.
+// It must be called with l.updateMu held.
+func (l *Launcher) finishAnswer(suffix string) {
+	answer := l.gptAnswer.String()
+	l.gptAnswer.Reset()
+	l.mu.Lock()
+	if answer != "" {
+		l.chat.Add(llm.RoleAssistant, answer+suffix)
+	} else if n := len(l.chat.Messages); n > 0 && l.chat.Messages[n-1].Role == llm.RoleUser {
+		l.chat.Messages = l.chat.Messages[:n-1]
+	}
+	var err error
+	if len(l.chat.Messages) == 0 {
+		// nothing was said, the next question starts the chat again
+		l.chat = nil
+	} else {
+		err = chat.Save(l.chat)
+	}
+	l.mu.Unlock()
+
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: false})
+	if err != nil {
+		l.Message("Error saving chat: " + err.Error())
+		return
+	}
+	l.Message("")
+}
+
+// newChat leaves the current chat, which stays saved, and starts an empty one.
+func (l *Launcher) newChat() {
+	l.mode(g.ModeAskGPT)
+	l.mu.Lock()
+	l.chat = nil
+	l.mu.Unlock()
+}
+
+// listChats shows the saved chats as results, choosing one resumes it.
+func (l *Launcher) listChats() {
+	l.mode(g.ModeAskGPT)
+	chats, err := chat.List()
+	if err != nil {
+		l.Message("Error reading chats: " + err.Error())
+		return
+	}
+	if len(chats) == 0 {
+		l.Message("No saved chats yet.")
+		return
+	}
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetResults, Results: chatResults(chats)})
+}
+
+func (l *Launcher) resumeChat(id string) {
+	c, err := chat.Load(id)
+	if err != nil {
+		l.Message("Error opening chat: " + err.Error())
+		return
+	}
+	l.cancelGPT()
+	l.mu.Lock()
+	l.chat = c
+	l.mu.Unlock()
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetResults})
+	l.Message("")
+}
+
+// maximum size of the text attached from a document or the clipboard
+const maxAttachment = 12000
+
+// templatePrompt runs ":g <name> <text>" through the named prompt template, or asks the
+// text as is when it doesn't start with a template name. Prompts with attached context
+// wait for a second Enter so the context can be checked first.
+func (l *Launcher) templatePrompt(input string) {
+	template, text, ok := prompts.Match(input)
+	if !ok {
+		l.askGPT(input)
+		return
+	}
+	sources := template.Sources()
+	if len(sources) == 0 {
+		l.askGPT(template.Expand(text, nil))
+		return
+	}
+
+	attached := make(map[prompts.Source]string)
+	var preview strings.Builder
+	for _, source := range sources {
+		value, description, err := l.attachment(source)
+		if err != nil {
+			l.Message("Can't attach the " + string(source) + ": " + err.Error())
+			return
+		}
+		attached[source] = value
+		fmt.Fprintf(&preview, "Attached %s:\n%s\n\n", description, excerpt(value, 300))
+	}
+
+	prompt := template.Expand(text, attached)
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandOpenDialog, Dialog: presentation.Dialog{Kind: presentation.DialogSendPrompt, Payload: prompt}})
+	l.Message(preview.String() + "Press Enter to send or Escape to discard.")
+}
+
+// attachment reads the context for a template, with a short description for the preview.
+func (l *Launcher) attachment(source prompts.Source) (value, description string, err error) {
+	l.mu.RLock()
+	document, window := l.selectedDocument, l.previousWindow
+	l.mu.RUnlock()
+
+	switch source {
+	case prompts.SourceClipboard:
+		value, err = clipboard.Text()
+		if len(value) > maxAttachment {
+			value = strings.ToValidUTF8(value[:maxAttachment], "")
+		}
+		return value, fmt.Sprintf("clipboard (%d characters)", utf8.RuneCountInString(value)), err
+	case prompts.SourceDocument:
+		if document == "" {
+			return "", "", errors.New("select a result in document search (:d) first")
+		}
+		value, truncated, err := documents.ReadText(document, maxAttachment)
+		description = filepath.Base(document)
+		if truncated {
+			description += " (first part)"
+		}
+		return value, description, err
+	case prompts.SourceWindow:
+		if window == "" {
+			return "", "", errors.New("no window was active before the launcher")
+		}
+		return window, "window title", nil
+	}
+	return "", "", fmt.Errorf("unknown source %q", source)
+}
+
+func excerpt(text string, length int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= length {
+		return string(runes)
+	}
+	return string(runes[:length]) + "..."
+}
+
+// sendPending sends the prompt shown for confirmation, if there is one.
+func (l *Launcher) sendPending() {
+	if dialog, ok := l.TakeDialog(presentation.DialogSendPrompt); ok {
+		l.askGPT(dialog.Payload.(string))
+	}
+}
+
+// discardPending drops the prompt shown for confirmation, reporting false if there was none.
+func (l *Launcher) discardPending() bool {
+	_, ok := l.TakeDialog(presentation.DialogSendPrompt)
+	if ok {
+		l.Message("")
+	}
+	return ok
+}
+
+// rememberActiveWindow keeps the title of the window the launcher is about to cover.
+func (l *Launcher) rememberActiveWindow() {
+	title, err := l.env.ActiveWindowTitle()
+	if err != nil || title == "" || title == g.AppName {
+		return
+	}
+	l.mu.Lock()
+	l.previousWindow = title
+	l.mu.Unlock()
+}
+
+// Transcript returns the lines of the current chat, without the answer being streamed.
+func (l *Launcher) Transcript() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.chat == nil {
+		return nil
+	}
+	var lines []string
+	for _, m := range l.chat.Messages {
+		if m.Role == llm.RoleUser {
+			lines = append(lines, "You: "+m.Content)
+		} else {
+			lines = append(lines, "GPT: "+m.Content)
+		}
+	}
+	return lines
+}
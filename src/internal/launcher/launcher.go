@@ -0,0 +1,470 @@
+// Package launcher is what the launcher does, without a window: keys and text go in,
+// presentation commands come out, and the ui package draws the resulting state.
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"winfastnav/internal/agent"
+	"winfastnav/internal/apps"
+	"winfastnav/internal/chat"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/llm"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/search"
+	"winfastnav/internal/utils"
+	"winfastnav/internal/windowmanager"
+	"winfastnav/internal/workspaces"
+)
+
+// Key is a key the launcher reacts to outside of typing.
+type Key uint8
+
+const (
+	KeyUp Key = iota
+	KeyDown
+	KeyEnter
+	KeyEscape
+	KeyDelete
+)
+
+// Opener starts what results point at.
+type Opener interface {
+	OpenProgram(commandLine string) error
+	OpenFile(path string) error
+	OpenURI(uri string) error
+}
+
+// Environment is what the launcher uses from the rest of the app. System returns the real one.
+type Environment struct {
+	Opener Opener
+	// Search returns the results or the message for query typed in mode.
+	Search            func(mode int, query string) ([]g.Resource, *string)
+	Windows           func() ([]apps.OpenWindow, error)
+	WindowAction      func(id uint64, action windowmanager.Action) error
+	ActiveWindowTitle func() (string, error)
+	// Block hides an app from program search and returns the rule that did it, for Unblock.
+	// added is false when an existing rule already hid it.
+	Block   func(g.Resource) (rule g.BlockRule, added bool, err error)
+	Unblock func(g.BlockRule) error
+	Reindex func()
+	LLM     func() llm.LLMClient
+	Route   func(ctx context.Context, request string) (agent.Result, error)
+	// Workspaces returns what saving and restoring workspaces use.
+	Workspaces func() (workspaces.Environment, error)
+	// Hide and Quit act on the window and the process once the launcher state is updated.
+	Hide func()
+	Quit func()
+}
+
+// Launcher drives a presentation.Controller. Its methods may be called from any goroutine.
+type Launcher struct {
+	controller *presentation.Controller
+	env        Environment
+
+	// updateMu orders what background requests show against cancelling them. It is taken
+	// before mu and never by what observers call, so changes can be dispatched holding it,
+	// which is never done holding mu.
+	updateMu sync.Mutex
+	mu       sync.RWMutex
+	// every switchable window, filtered into the results as the query changes
+	openWindows                      []apps.OpenWindow
+	lastBlocked                      *g.BlockRule
+	chat                             *chat.Chat
+	gptAnswer                        strings.Builder
+	selectedDocument, previousWindow string
+	routeCancel                      context.CancelFunc
+	routeRequest                     int
+	gptCancel                        context.CancelFunc
+	gptRequest                       int
+	restoreCancel                    context.CancelFunc
+	restoreRequest                   int
+}
+
+func New(controller *presentation.Controller, env Environment) *Launcher {
+	return &Launcher{controller: controller, env: env}
+}
+
+// Show brings up the launcher with an empty query in mode, in a single change so no frame
+// shows the window before its mode is set.
+func (l *Launcher) Show(mode int) {
+	l.cancelGPT()
+	l.cancelRoute()
+	l.cancelRestore()
+	l.clearUndo()
+	commands := append([]presentation.Command{
+		{Kind: presentation.CommandShow},
+		{Kind: presentation.CommandSetPage, Page: presentation.PageLauncher},
+		{Kind: presentation.CommandSetQuery},
+	}, l.enterMode(mode)...)
+	if mode == g.ModeSearchProgram {
+		commands = append(commands, message(g.AppName+"\nMenu -> Help"))
+	}
+	l.controller.Batch(commands...)
+	l.rememberActiveWindow()
+}
+
+// ShowQuery brings up the launcher in its current mode with text typed into the search box.
+func (l *Launcher) ShowQuery(text string) {
+	l.controller.Batch(
+		presentation.Command{Kind: presentation.CommandShow},
+		presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageLauncher},
+	)
+	l.Type(text)
+	l.rememberActiveWindow()
+}
+
+// Hide puts the launcher away, dropping the query, results and any question.
+func (l *Launcher) Hide() {
+	l.cancelGPT()
+	l.cancelRestore()
+	l.clearWindows()
+	l.clearUndo()
+	l.controller.Batch(
+		presentation.Command{Kind: presentation.CommandSetQuery},
+		presentation.Command{Kind: presentation.CommandSetResults},
+		presentation.Command{Kind: presentation.CommandCloseDialog},
+		presentation.Command{Kind: presentation.CommandHide},
+	)
+	if l.env.Hide != nil {
+		l.env.Hide()
+	}
+}
+
+// Press handles a key. Escape first stops or dismisses what is in progress and only then hides.
+func (l *Launcher) Press(k Key) {
+	s := l.controller.Snapshot()
+	switch k {
+	case KeyEscape:
+		// the first Escape only stops a Quick GPT answer, keeping what arrived so far,
+		// or discards a question waiting for an answer
+		if l.cancelGPT() || l.discardPending() || l.cancelRoute() || l.cancelRestore() || l.cancelKill() {
+			return
+		}
+		l.Hide()
+	case KeyUp:
+		l.Select(s.Selected - 1)
+	case KeyDown:
+		l.Select(s.Selected + 1)
+	case KeyEnter:
+		l.Submit(s.Query)
+	case KeyDelete:
+		if s.Mode == g.ModeSearchProgram && s.Selected >= 0 {
+			l.Block(s.Selected)
+		}
+	}
+}
+
+// Type replaces the query, updating the results of the current mode.
+func (l *Launcher) Type(query string) {
+	state, _ := l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetQuery, Query: query})
+	mode := state.Mode
+	if mode == g.ModeChooseProgram {
+		l.filterWindows(query)
+		return
+	}
+	// the transcript takes the place of results while chatting, commands only run on Enter
+	if mode == g.ModeAskGPT || mode == g.ModeCommand {
+		return
+	}
+	items, text := l.env.Search(mode, query)
+	if text != nil {
+		l.controller.Batch(presentation.Command{Kind: presentation.CommandSetResults}, message(*text))
+		return
+	}
+	kind := presentation.ResultApp
+	if mode == g.ModeSearchDocument {
+		kind = presentation.ResultDocument
+	} else if mode != g.ModeSearchProgram {
+		return
+	}
+	l.controller.Batch(presentation.Command{Kind: presentation.CommandSetResults, Results: resourceResults(kind, items, query)}, message(""))
+}
+
+// Submit runs what Enter does with input in the search box: a ":" command, a confirmation
+// when input is empty, or the current mode's action.
+func (l *Launcher) Submit(input string) {
+	if l.confirmKill() {
+		return
+	}
+	s := l.controller.Snapshot()
+	if input == "" {
+		switch s.Mode {
+		case g.ModeAskGPT:
+			l.sendPending()
+		case g.ModeCommand:
+			l.confirmAction()
+		}
+		return
+	}
+	if strings.HasPrefix(input, ":") {
+		l.clearQuery()
+		l.command(input)
+		return
+	}
+	if s.Mode != g.ModeAskGPT && s.Mode != g.ModeChooseProgram && s.Mode != g.ModeCommand {
+		if engine, terms, ok := search.Match(input, s.Mode == g.ModeSearchInternet); ok {
+			l.webSearch(search.URL(engine, terms))
+			return
+		}
+	}
+	if strings.HasPrefix(input, "=") {
+		expr := strings.ReplaceAll(strings.TrimPrefix(input, "="), " ", "")
+		if utils.IsMath(expr) {
+			if result, err := utils.EvalMath(expr); err == nil {
+				l.Type(result)
+				return
+			}
+		}
+	}
+	switch s.Mode {
+	case g.ModeAskGPT:
+		l.clearQuery()
+		l.askGPT(input)
+	case g.ModeCommand:
+		l.clearQuery()
+		l.route(input)
+	case g.ModeChooseProgram:
+		// the top match unless another one was selected
+		if selected := max(s.Selected, 0); selected < len(s.Results) {
+			l.focusWindow(s.Results[selected].Window)
+		}
+	case g.ModeSearchInternet:
+		l.webSearch(search.URL(search.Default(), input))
+	default:
+		if s.Selected >= 0 {
+			l.Open(s.Selected)
+		}
+	}
+}
+
+func (l *Launcher) command(input string) {
+	if len(input) == 1 {
+		l.Message("Enter a command. Menu -> Help lists the available commands.")
+		return
+	}
+	if strings.HasPrefix(input, ":ws") {
+		l.workspace(input[3:])
+		return
+	}
+	switch input[1] {
+	case 'p':
+		l.mode(g.ModeSearchProgram)
+	case 'd':
+		l.mode(g.ModeSearchDocument)
+	case 'w':
+		l.mode(g.ModeSearchInternet)
+	case 's':
+		l.mode(g.ModeChooseProgram)
+	case 'g':
+		l.mode(g.ModeAskGPT)
+		if rest := strings.TrimSpace(input[2:]); rest != "" {
+			l.templatePrompt(rest)
+		}
+	case 'a':
+		l.mode(g.ModeCommand)
+	case 'n':
+		l.newChat()
+	case 'c':
+		l.listChats()
+	case 'r':
+		l.Message("Re-indexing programs and documents.")
+		if l.env.Reindex != nil {
+			l.env.Reindex()
+		}
+	case 'q':
+		l.Hide()
+	case 'x':
+		if l.env.Quit != nil {
+			l.env.Quit()
+		}
+	}
+}
+
+func (l *Launcher) clearQuery() {
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetQuery})
+}
+
+func (l *Launcher) focusWindow(id uint64) {
+	if err := l.env.WindowAction(id, windowmanager.ActionFocus); err != nil {
+		l.Message("Error switching window: " + err.Error())
+		return
+	}
+	l.Hide()
+}
+
+func (l *Launcher) webSearch(uri string) {
+	if err := l.env.Opener.OpenURI(uri); err != nil {
+		l.Message("Sorry, there was an error opening your web browser: " + err.Error())
+		return
+	}
+	l.Hide()
+}
+
+func (l *Launcher) mode(mode int) {
+	l.cancelGPT()
+	l.cancelRoute()
+	l.controller.Batch(l.enterMode(mode)...)
+}
+
+// enterMode returns the commands switching to mode, listing the windows for the switcher.
+// Results and dialogs go with the mode they belonged to.
+func (l *Launcher) enterMode(mode int) []presentation.Command {
+	var windows []apps.OpenWindow
+	text := ""
+	if mode == g.ModeChooseProgram {
+		var err error
+		if windows, err = l.env.Windows(); err != nil {
+			text = "Error listing windows: " + err.Error()
+		}
+	}
+	l.mu.Lock()
+	l.openWindows = windows
+	l.mu.Unlock()
+	commands := []presentation.Command{{Kind: presentation.CommandSetMode, Mode: mode}, message(text)}
+	if windows != nil {
+		commands = append(commands, presentation.Command{Kind: presentation.CommandSetResults, Results: windowResults(windows, "")})
+	}
+	return commands
+}
+
+// filterWindows narrows the switcher to the windows matching query and selects the best match.
+func (l *Launcher) filterWindows(query string) {
+	l.mu.RLock()
+	windows := apps.FilterWindows(l.openWindows, query)
+	l.mu.RUnlock()
+	results := presentation.Command{Kind: presentation.CommandSetResults, Results: windowResults(windows, query)}
+	switch {
+	case query == "":
+		l.controller.Dispatch(results)
+	case len(windows) == 0:
+		l.controller.Batch(results, message("No window matches."))
+	default:
+		l.controller.Batch(results, message(""), presentation.Command{Kind: presentation.CommandSelectResult, Selected: 0})
+	}
+}
+
+// Select moves the selection to the result at index, kept within the results.
+func (l *Launcher) Select(index int) {
+	s := l.controller.Snapshot()
+	if len(s.Results) == 0 {
+		return
+	}
+	index = min(max(index, 0), len(s.Results)-1)
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSelectResult, Selected: index})
+	if s.Results[index].Kind == presentation.ResultDocument {
+		l.mu.Lock()
+		l.selectedDocument = s.Results[index].Target
+		l.mu.Unlock()
+	}
+}
+
+// Open opens the result at index and hides the launcher.
+func (l *Launcher) Open(index int) {
+	if l.confirmKill() {
+		return
+	}
+	s := l.controller.Snapshot()
+	if index < 0 || index >= len(s.Results) {
+		return
+	}
+	result := s.Results[index]
+	var err error
+	switch result.Kind {
+	case presentation.ResultChat:
+		l.resumeChat(result.Target)
+		return
+	case presentation.ResultWindow:
+		l.focusWindow(result.Window)
+		return
+	case presentation.ResultApp:
+		err = l.env.Opener.OpenProgram(result.Target)
+	case presentation.ResultDocument:
+		err = l.env.Opener.OpenFile(result.Target)
+	}
+	if err != nil {
+		l.Message("Sorry, there was an error opening the selected item: " + err.Error())
+		return
+	}
+	l.Hide()
+}
+
+// Block hides the app at index from program search until UndoBlock.
+func (l *Launcher) Block(index int) {
+	s := l.controller.Snapshot()
+	if index < 0 || index >= len(s.Results) || s.Results[index].Kind != presentation.ResultApp {
+		return
+	}
+	item := g.Resource{Name: s.Results[index].Title, Filepath: s.Results[index].Target}
+	rule, added, err := l.env.Block(item)
+	if err != nil {
+		l.Message("Error hiding " + item.Name + ": " + err.Error())
+		return
+	}
+	l.mu.Lock()
+	// undoing a rule that was there before would unhide more than this app
+	l.lastBlocked = nil
+	if added {
+		l.lastBlocked = &rule
+	}
+	l.mu.Unlock()
+	l.Type(s.Query)
+	if !added {
+		l.Message(fmt.Sprintf("%s is already hidden by the blocklist.", item.Name))
+		return
+	}
+	l.Message(fmt.Sprintf("%s is now hidden. Click Undo to bring it back.", item.Name))
+}
+
+// UndoBlock removes the rule added by the last Block.
+func (l *Launcher) UndoBlock() {
+	l.mu.Lock()
+	rule := l.lastBlocked
+	l.lastBlocked = nil
+	l.mu.Unlock()
+	if rule == nil {
+		return
+	}
+	if err := l.env.Unblock(*rule); err != nil {
+		l.Message("Error undoing: " + err.Error())
+		return
+	}
+	l.Type(l.controller.Snapshot().Query)
+}
+
+// CanUndo reports whether there is a Block to undo.
+func (l *Launcher) CanUndo() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastBlocked != nil
+}
+
+func (l *Launcher) clearWindows() {
+	l.mu.Lock()
+	l.openWindows = nil
+	l.mu.Unlock()
+}
+func (l *Launcher) clearUndo() { l.mu.Lock(); l.lastBlocked = nil; l.mu.Unlock() }
+
+// Message shows text below the search box, wrapped to fit.
+func (l *Launcher) Message(text string) {
+	l.controller.Dispatch(message(text))
+}
+
+func message(text string) presentation.Command {
+	return presentation.Command{Kind: presentation.CommandSetMessage, Message: utils.WrapTextByWords(text, 64)}
+}
+
+// TakeDialog closes the dialog if it is of kind and returns it, reporting false when
+// another one or none is open.
+func (l *Launcher) TakeDialog(kind presentation.DialogKind) (presentation.Dialog, bool) {
+	dialog := l.controller.Snapshot().Dialog
+	if kind == presentation.DialogNone || dialog.Kind != kind {
+		return dialog, false
+	}
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandCloseDialog})
+	return dialog, true
+}
@@ -0,0 +1,49 @@
+package launcher
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"winfastnav/internal/apps"
+	"winfastnav/internal/chat"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/presentation"
+)
+
+// MaxResults is the most results the launcher lists at once.
+const MaxResults = 30
+
+// resourceResults lists apps by their publisher, or their command line when it isn't known,
+// and documents by their folder, since several documents often share a name.
+func resourceResults(kind presentation.ResultKind, items []g.Resource, query string) []presentation.Result {
+	results := make([]presentation.Result, 0, len(items))
+	for _, item := range items {
+		detail := item.Filepath
+		if kind == presentation.ResultDocument {
+			detail = filepath.Dir(item.Filepath)
+		} else if item.Publisher != "" {
+			detail = item.Publisher
+		}
+		results = append(results, presentation.Result{Kind: kind, Title: item.Name, Target: item.Filepath, Detail: detail, Matches: apps.MatchPositions(query, item.Name)})
+	}
+	return results
+}
+
+func windowResults(windows []apps.OpenWindow, query string) []presentation.Result {
+	results := make([]presentation.Result, 0, len(windows))
+	for _, w := range windows {
+		results = append(results, presentation.Result{Kind: presentation.ResultWindow, Title: w.Title, Detail: w.Process, Window: w.ID, Number: w.Number, Icon: w.Icon, Matches: apps.MatchPositions(query, w.Title)})
+	}
+	return results
+}
+
+// chatResults lists the first MaxResults chats, the most recent ones.
+func chatResults(chats []chat.Summary) []presentation.Result {
+	chats = chats[:min(len(chats), MaxResults)]
+	results := make([]presentation.Result, 0, len(chats))
+	for _, c := range chats {
+		detail := fmt.Sprintf("%d messages, %s", c.Messages, c.Updated.Format("Jan 2 15:04"))
+		results = append(results, presentation.Result{Kind: presentation.ResultChat, Title: c.Title, Target: c.ID, Detail: detail})
+	}
+	return results
+}
@@ -0,0 +1,65 @@
+package launcher
+
+import (
+	"context"
+	"time"
+
+	"winfastnav/internal/agent"
+	"winfastnav/internal/apps"
+	"winfastnav/internal/core"
+	"winfastnav/internal/documents"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/llm"
+	"winfastnav/internal/opener"
+	"winfastnav/internal/windowmanager"
+	"winfastnav/internal/workspaces"
+)
+
+// how long a restore looks for the windows of the programs it started
+const workspaceWait = 20 * time.Second
+
+type systemOpener struct{}
+
+func (systemOpener) OpenProgram(commandLine string) error { return apps.OpenProgram(commandLine) }
+func (systemOpener) OpenFile(path string) error           { return opener.OpenFile(path) }
+func (systemOpener) OpenURI(uri string) error             { return opener.OpenURI(uri) }
+
+// System returns the environment of the running app, with hide and quit acting on its window.
+func System(hide, quit func()) Environment {
+	return Environment{
+		Opener:            systemOpener{},
+		Search:            core.HandleTextInput,
+		Windows:           apps.GetOpenWindows,
+		WindowAction:      apps.WindowAction,
+		ActiveWindowTitle: apps.ActiveWindowTitle,
+		Block:             apps.BlockApplication,
+		Unblock:           apps.UnblockRule,
+		Reindex: func() {
+			go documents.SetupDocs()
+			go apps.SetupApps()
+		},
+		LLM: llm.New,
+		Route: func(ctx context.Context, request string) (agent.Result, error) {
+			return agent.New().Route(ctx, request)
+		},
+		Workspaces: workspaceEnvironment,
+		Hide:       hide,
+		Quit:       quit,
+	}
+}
+
+func workspaceEnvironment() (workspaces.Environment, error) {
+	wm, err := apps.WindowManager()
+	if err != nil {
+		return workspaces.Environment{}, err
+	}
+	return workspaces.Environment{
+		WM:        wm,
+		Skip:      func(w windowmanager.Window) bool { return w.Title == g.AppName },
+		Program:   apps.ProgramFor,
+		Documents: documents.Documents,
+		Launch:    apps.StartProgram,
+		Open:      opener.OpenFile,
+		Wait:      workspaceWait,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package launcher
+
+import (
+	"slices"
+
+	"winfastnav/internal/apps"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/windowmanager"
+)
+
+// WindowAction runs action on the selected window in the switcher, or the top match.
+// Killing a process waits for confirmation.
+func (l *Launcher) WindowAction(action windowmanager.Action) {
+	s := l.controller.Snapshot()
+	if s.Mode != g.ModeChooseProgram {
+		return
+	}
+	selected := max(s.Selected, 0)
+	if selected >= len(s.Results) {
+		return
+	}
+	window := s.Results[selected]
+	if action == windowmanager.ActionKill {
+		l.controller.Dispatch(presentation.Command{Kind: presentation.CommandOpenDialog, Dialog: presentation.Dialog{Kind: presentation.DialogKillProcess, Payload: window}})
+		l.Message("End " + owner(window) + ", closing \"" + window.Title + "\" and every other window it has open? Unsaved work is lost.\n\nPress Enter to confirm or Escape to cancel.")
+		return
+	}
+	l.windowAction(window, action)
+}
+
+// windowAction performs action on window. Windows that were moved or resized are brought up,
+// while closing, minimizing and killing keep the switcher open for the next window.
+func (l *Launcher) windowAction(window presentation.Result, action windowmanager.Action) {
+	if err := l.env.WindowAction(window.Window, action); err != nil {
+		l.Message("Error: " + err.Error())
+		return
+	}
+	switch action {
+	case windowmanager.ActionMinimize:
+		l.Message("Minimized " + window.Title + ".")
+	case windowmanager.ActionClose, windowmanager.ActionKill:
+		l.mu.Lock()
+		l.openWindows = slices.DeleteFunc(slices.Clone(l.openWindows), func(w apps.OpenWindow) bool { return w.ID == window.Window })
+		l.mu.Unlock()
+		results := slices.DeleteFunc(slices.Clone(l.controller.Snapshot().Results), func(r presentation.Result) bool { return r.Window == window.Window })
+		l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetResults, Results: results})
+		if len(results) > 0 {
+			l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSelectResult, Selected: 0})
+		}
+		if action == windowmanager.ActionKill {
+			l.Message("Ended " + owner(window) + ".")
+		} else {
+			l.Message("Closed " + window.Title + ".")
+		}
+	default:
+		l.focusWindow(window.Window)
+	}
+}
+
+func owner(window presentation.Result) string {
+	if window.Detail == "" {
+		return "the program"
+	}
+	return window.Detail
+}
+
+// confirmKill ends the process waiting for confirmation, reporting false if there was none.
+func (l *Launcher) confirmKill() bool {
+	dialog, ok := l.TakeDialog(presentation.DialogKillProcess)
+	if !ok {
+		return false
+	}
+	l.windowAction(dialog.Payload.(presentation.Result), windowmanager.ActionKill)
+	return true
+}
+
+// cancelKill forgets the process waiting for confirmation, reporting false if there was none.
+func (l *Launcher) cancelKill() bool {
+	_, ok := l.TakeDialog(presentation.DialogKillProcess)
+	if ok {
+		l.Message("")
+	}
+	return ok
+}
@@ -0,0 +1,143 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/workspaces"
+)
+
+// workspace runs ":ws" with its arguments: nothing lists the workspaces, "save <name> [+docs]"
+// and "delete <name>" manage them, and a name restores one.
+func (l *Launcher) workspace(args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 0:
+		l.listWorkspaces()
+	case fields[0] == "save" && len(fields) >= 2:
+		l.saveWorkspace(fields[1], len(fields) > 2 && fields[2] == "+docs")
+	case fields[0] == "delete" && len(fields) == 2:
+		if err := workspaces.Delete(fields[1]); err != nil {
+			l.Message("Error: " + err.Error())
+			return
+		}
+		l.Message("Deleted workspace " + fields[1] + ".")
+	case len(fields) == 1:
+		l.restoreWorkspace(fields[0])
+	default:
+		l.Message("Use :ws <name>, :ws save <name> [+docs] or :ws delete <name>.")
+	}
+}
+
+func (l *Launcher) listWorkspaces() {
+	list, err := workspaces.List()
+	if err != nil {
+		l.Message("Error reading workspaces: " + err.Error())
+		return
+	}
+	if len(list) == 0 {
+		l.Message("No saved workspaces. Arrange your windows, then save them with :ws save <name>, adding +docs to reopen documents too.")
+		return
+	}
+	var b strings.Builder
+	b.WriteString("Workspaces:\n")
+	for _, w := range list {
+		fmt.Fprintf(&b, "%s (%d windows", w.Name, len(w.Windows))
+		if len(w.Documents) > 0 {
+			fmt.Fprintf(&b, ", %d documents", len(w.Documents))
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString("\n:ws <name> restores one.")
+	l.Message(b.String())
+}
+
+func (l *Launcher) saveWorkspace(name string, withDocuments bool) {
+	env, err := l.env.Workspaces()
+	if err != nil {
+		l.Message("Error: " + err.Error())
+		return
+	}
+	workspace, err := env.Capture(name, withDocuments)
+	if err == nil {
+		err = workspaces.Save(workspace)
+	}
+	if err != nil {
+		l.Message("Error saving workspace: " + err.Error())
+		return
+	}
+	saved := fmt.Sprintf("Saved %d windows", len(workspace.Windows))
+	if len(workspace.Documents) > 0 {
+		saved += fmt.Sprintf(" and %d documents", len(workspace.Documents))
+	}
+	l.Message(saved + " as " + workspace.Name + ".")
+}
+
+// restoreWorkspace moves and starts the windows in the background, since started programs
+// take a while to show their windows. It replaces a restore still in progress.
+func (l *Launcher) restoreWorkspace(name string) {
+	l.cancelRestore()
+	workspace, err := workspaces.Find(name)
+	if err != nil {
+		l.Message("Error: " + err.Error())
+		return
+	}
+	env, err := l.env.Workspaces()
+	if err != nil {
+		l.Message("Error: " + err.Error())
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	l.restoreRequest++
+	id := l.restoreRequest
+	l.restoreCancel = cancel
+	l.mu.Unlock()
+
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: true})
+	l.Message("Restoring " + workspace.Name + "...")
+	go func() {
+		defer cancel()
+		report, err := env.Restore(ctx, workspace)
+		l.updateMu.Lock()
+		defer l.updateMu.Unlock()
+		l.mu.Lock()
+		current := l.restoreRequest == id
+		if current {
+			l.restoreCancel = nil
+		}
+		l.mu.Unlock()
+		if !current {
+			return
+		}
+		l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: false})
+		if err != nil {
+			l.Message("Error restoring workspace: " + err.Error())
+			return
+		}
+		l.Message(report.String())
+	}()
+}
+
+// cancelRestore stops waiting for the windows of a workspace being restored, reporting false
+// if no restore was running. Windows already moved stay where they are.
+func (l *Launcher) cancelRestore() bool {
+	l.updateMu.Lock()
+	defer l.updateMu.Unlock()
+	l.mu.Lock()
+	cancel := l.restoreCancel
+	l.restoreCancel = nil
+	if cancel != nil {
+		l.restoreRequest++
+	}
+	l.mu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	l.controller.Dispatch(presentation.Command{Kind: presentation.CommandSetLoading, Loading: false})
+	l.Message("")
+	return true
+}
@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"winfastnav/internal/settings"
+)
+
+// Message roles of the chat completions API.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// LLMClient answers a conversation with the next assistant message.
+type LLMClient interface {
+	Complete(ctx context.Context, messages []Message) (string, error)
+}
+
+// Streamer is implemented by clients that can hand out the answer while it's being generated.
+// Stream calls onToken for each new piece and returns the whole answer at the end.
+type Streamer interface {
+	Stream(ctx context.Context, messages []Message, onToken func(string)) (string, error)
+}
+
+// ErrTimeout means the server stopped sending data for longer than the configured timeout.
+var ErrTimeout = errors.New("the model stopped responding")
+
+// Config is the "llm" setting. The defaults talk to Ollama on this machine;
+// llama.cpp server and LM Studio only need BaseURL changed. TimeoutSeconds is how
+// long the server may stay silent before the request is given up.
+type Config struct {
+	BaseURL        string  `json:"baseURL"`
+	Model          string  `json:"model"`
+	APIKey         string  `json:"apiKey,omitempty"`
+	SystemPrompt   string  `json:"systemPrompt,omitempty"`
+	Temperature    float64 `json:"temperature"`
+	TimeoutSeconds int     `json:"timeoutSeconds"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:        "http://localhost:11434/v1",
+		Model:          "llama3.2",
+		SystemPrompt:   "You are a helpful assistant inside a desktop launcher. Answer briefly, in plain text without markdown.",
+		Temperature:    0.7,
+		TimeoutSeconds: 120,
+	}
+}
+
+// Validate checks the fields that would make every request fail.
+func (c Config) Validate() error {
+	var errs []error
+	if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("base URL %q must be an http(s) URL", c.BaseURL))
+	}
+	if strings.TrimSpace(c.Model) == "" {
+		errs = append(errs, errors.New("model is required"))
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		errs = append(errs, errors.New("temperature must be between 0 and 2"))
+	}
+	if c.TimeoutSeconds <= 0 {
+		errs = append(errs, errors.New("timeout must be a positive number of seconds"))
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	config   Config
+	configMu sync.RWMutex
+	loadOnce sync.Once
+)
+
+// GetConfig returns the saved configuration, or the defaults.
+func GetConfig() Config {
+	loadOnce.Do(loadConfig)
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return config
+}
+
+// SetConfig validates and saves the configuration.
+func SetConfig(c Config) error {
+	loadOnce.Do(loadConfig)
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	c.Model = strings.TrimSpace(c.Model)
+	c.APIKey = strings.TrimSpace(c.APIKey)
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err = settings.SetSetting("llm", string(data)); err != nil {
+		return err
+	}
+	configMu.Lock()
+	config = c
+	configMu.Unlock()
+	return nil
+}
+
+func loadConfig() {
+	c := DefaultConfig()
+	unparsed, err := settings.GetSetting("llm")
+	if err == nil && unparsed != "" {
+		if err = json.Unmarshal([]byte(unparsed), &c); err != nil {
+			log.Printf("Error parsing LLM settings: %v", err)
+			c = DefaultConfig()
+		}
+	}
+
+	configMu.Lock()
+	config = c
+	configMu.Unlock()
+}
+
+// New returns a client for the saved configuration.
+func New() LLMClient {
+	return NewOpenAI(GetConfig())
+}
+
+// Stream sends the conversation and delivers the answer piece by piece. Clients that can't stream
+// hand over the whole answer as one piece.
+func Stream(ctx context.Context, client LLMClient, messages []Message, onToken func(string)) (string, error) {
+	if streamer, ok := client.(Streamer); ok {
+		return streamer.Stream(ctx, messages, onToken)
+	}
+	answer, err := client.Complete(ctx, messages)
+	if err == nil {
+		onToken(answer)
+	}
+	return answer, err
+}
@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// maximum size of an error body worth reading for its message
+	maxErrorBody = 64 << 10
+	// maximum size of a single server-sent event line
+	maxEventLine = 1 << 20
+)
+
+// OpenAIClient talks to any server with an OpenAI-compatible /chat/completions endpoint.
+type OpenAIClient struct {
+	config Config
+	http   *http.Client
+}
+
+func NewOpenAI(config Config) *OpenAIClient {
+	return &OpenAIClient{config: config, http: &http.Client{}}
+}
+
+type chatRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+}
+
+type chatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *OpenAIClient) timeout() time.Duration {
+	return time.Duration(c.config.TimeoutSeconds) * time.Second
+}
+
+// Complete sends the conversation, with the system prompt first, and returns the reply.
+// The whole answer has to arrive within the configured timeout.
+func (c *OpenAIClient) Complete(ctx context.Context, messages []Message) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+	resp, err := c.post(ctx, messages, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var parsed chatResponse
+	if err = json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", errors.New("the model returned no answer")
+	}
+	return strings.TrimSpace(parsed.Choices[0].Message.Content), nil
+}
+
+// Stream asks for a server-sent-events response and calls onToken with each piece of the
+// answer as it arrives. The timeout applies to the wait between events rather than the whole
+// answer, so long answers from slow local models aren't cut off.
+func (c *OpenAIClient) Stream(ctx context.Context, messages []Message, onToken func(string)) (string, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	idle := time.AfterFunc(c.timeout(), func() { cancel(ErrTimeout) })
+	defer idle.Stop()
+
+	answer, err := c.stream(ctx, messages, func() { idle.Reset(c.timeout()) }, onToken)
+	if err != nil && errors.Is(context.Cause(ctx), ErrTimeout) {
+		err = fmt.Errorf("%w after %s without data", ErrTimeout, c.timeout())
+	}
+	return answer, err
+}
+
+func (c *OpenAIClient) stream(ctx context.Context, messages []Message, alive func(), onToken func(string)) (string, error) {
+	resp, err := c.post(ctx, messages, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	err = readEvents(resp.Body, func(data string) error {
+		alive()
+		if data == "[DONE]" {
+			return errDone
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("invalid event: %w", err)
+		}
+		if chunk.Error != nil {
+			return errors.New(chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+		if token := chunk.Choices[0].Delta.Content; token != "" {
+			// leading whitespace of the answer is dropped, as Complete trims it
+			if answer.Len() == 0 {
+				token = strings.TrimLeft(token, " \t\r\n")
+			}
+			if token != "" {
+				answer.WriteString(token)
+				onToken(token)
+			}
+		}
+		if chunk.Choices[0].FinishReason != nil && *chunk.Choices[0].FinishReason != "" {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDone) {
+		return answer.String(), err
+	}
+	if answer.Len() == 0 {
+		return "", errors.New("the model returned no answer")
+	}
+	return strings.TrimRight(answer.String(), " \t\r\n"), nil
+}
+
+var errDone = errors.New("stream finished")
+
+// readEvents splits a text/event-stream body into events and passes each one's data to handle,
+// stopping at the first error handle returns. Comments and fields other than data are ignored.
+func readEvents(body io.Reader, handle func(data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), maxEventLine)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if err := handle(strings.Join(data, "\n")); err != nil {
+					return err
+				}
+				data = data[:0]
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		if field == "data" {
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		return handle(strings.Join(data, "\n"))
+	}
+	return nil
+}
+
+// post sends the request and returns the response if the server accepted it.
+func (c *OpenAIClient) post(ctx context.Context, messages []Message, stream bool) (*http.Response, error) {
+	if c.config.SystemPrompt != "" {
+		messages = append([]Message{{Role: RoleSystem, Content: c.config.SystemPrompt}}, messages...)
+	}
+	return c.send(ctx, chatRequest{Model: c.config.Model, Messages: messages, Temperature: c.config.Temperature, Stream: stream}, stream)
+}
+
+// send posts a JSON request to the chat completions endpoint.
+func (c *OpenAIClient) send(ctx context.Context, request any, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.config.BaseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		var parsed errorResponse
+		if json.Unmarshal(data, &parsed) == nil && parsed.Error.Message != "" {
+			return nil, fmt.Errorf("request failed: %s: %s", resp.Status, parsed.Error.Message)
+		}
+		return nil, fmt.Errorf("request failed: %s", resp.Status)
+	}
+	return resp, nil
+}
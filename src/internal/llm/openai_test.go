@@ -0,0 +1,270 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompleteSendsChatRequest(t *testing.T) {
+	var got chatRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  Paris.\n"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAI(Config{BaseURL: server.URL + "/v1/", Model: "llama3.2", APIKey: "secret", SystemPrompt: "Be brief.", Temperature: 0.2, TimeoutSeconds: 5})
+	answer, err := client.Complete(context.Background(), []Message{{Role: RoleUser, Content: "Capital of France?"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Paris." {
+		t.Errorf("answer = %q", answer)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Model != "llama3.2" || got.Temperature != 0.2 || len(got.Messages) != 2 ||
+		got.Messages[0] != (Message{RoleSystem, "Be brief."}) || got.Messages[1] != (Message{RoleUser, "Capital of France?"}) {
+		t.Errorf("unexpected request body %+v", got)
+	}
+}
+
+func TestCompleteWithoutAPIKeyOrSystemPrompt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("local servers shouldn't get an Authorization header")
+		}
+		var req chatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) != 1 {
+			t.Errorf("expected only the user message, got %+v", req.Messages)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewOpenAI(Config{BaseURL: server.URL, Model: "m", TimeoutSeconds: 5}).Complete(context.Background(), []Message{{Role: RoleUser, Content: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompleteReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("case") {
+		case "empty":
+			_, _ = w.Write([]byte(`{"choices":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"model \"gpt-9\" not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewOpenAI(Config{BaseURL: server.URL, Model: "gpt-9", TimeoutSeconds: 5}).Complete(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), `model "gpt-9" not found`) {
+		t.Errorf("expected the server's error message, got %v", err)
+	}
+	_, err = NewOpenAI(Config{BaseURL: server.URL + "/?case=empty#", Model: "m", TimeoutSeconds: 5}).Complete(context.Background(), nil)
+	if err == nil {
+		t.Error("expected an error for an empty answer")
+	}
+}
+
+func TestCompleteHonorsTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the server only notices the client hanging up once the body has been read
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := NewOpenAI(Config{BaseURL: server.URL, Model: "m", TimeoutSeconds: 5}).Complete(ctx, nil); err == nil {
+		t.Fatal("expected the request to be cancelled")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("defaults should be valid: %v", err)
+	}
+	bad := Config{BaseURL: "localhost:11434", Temperature: 3}
+	err := bad.Validate()
+	if err == nil || len(err.(interface{ Unwrap() []error }).Unwrap()) != 4 {
+		t.Fatalf("expected 4 problems, got %v", err)
+	}
+}
+
+func streamServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("expected a streaming request, got %+v", req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			_, _ = w.Write([]byte(event))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStreamDeliversTokens(t *testing.T) {
+	server := streamServer(t,
+		": keep-alive\n\n",
+		"data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n",
+		"data: {\"choices\":[{\"delta\":{\"content\":\"\\nHello\"}}]}\n\n",
+		"event: message\ndata: {\"choices\":[{\"delta\":{\"content\":\", world\"}}]}\n\n",
+		"data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n",
+		"data: [DONE]\n\n",
+	)
+
+	var tokens []string
+	answer, err := NewOpenAI(Config{BaseURL: server.URL, Model: "m", TimeoutSeconds: 5}).Stream(context.Background(), nil, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Hello, world" || strings.Join(tokens, "|") != "Hello|, world" {
+		t.Errorf("answer %q from tokens %q", answer, tokens)
+	}
+}
+
+func TestStreamReportsErrorEvents(t *testing.T) {
+	server := streamServer(t,
+		"data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n",
+		"data: {\"error\":{\"message\":\"context length exceeded\"}}\n\n",
+	)
+	answer, err := NewOpenAI(Config{BaseURL: server.URL, Model: "m", TimeoutSeconds: 5}).Stream(context.Background(), nil, func(string) {})
+	if err == nil || err.Error() != "context length exceeded" || answer != "partial" {
+		t.Errorf("got %q, %v", answer, err)
+	}
+}
+
+func TestStreamTimesOutWhenServerGoesQuiet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewOpenAI(Config{BaseURL: server.URL, Model: "m", TimeoutSeconds: 1})
+	start := time.Now()
+	_, err := client.Stream(context.Background(), nil, func(string) {})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("timeout took %s", elapsed)
+	}
+}
+
+func TestStreamStopsWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; ; i++ {
+			if _, err := w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"x\"}}]}\n\n")); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tokens := 0
+	_, err := NewOpenAI(Config{BaseURL: server.URL, Model: "m", TimeoutSeconds: 5}).Stream(ctx, nil, func(string) {
+		if tokens++; tokens == 3 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+type completer struct{ answer string }
+
+func (c completer) Complete(context.Context, []Message) (string, error) { return c.answer, nil }
+
+func TestStreamFallsBackToComplete(t *testing.T) {
+	var tokens []string
+	answer, err := Stream(context.Background(), completer{"all at once"}, nil, func(token string) { tokens = append(tokens, token) })
+	if err != nil || answer != "all at once" || len(tokens) != 1 || tokens[0] != answer {
+		t.Errorf("got %q %q %v", answer, tokens, err)
+	}
+}
+
+func TestCallToolsSendsDefinitionsAndParsesCalls(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[` +
+			`{"id":"call_a","type":"function","function":{"name":"search_apps","arguments":"{\"query\":\"slack\"}"}},` +
+			`{"type":"function","function":{"name":"calculate"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAI(Config{BaseURL: server.URL, Model: "qwen2.5", SystemPrompt: "Use the tools.", TimeoutSeconds: 5})
+	tools := []Tool{{Name: "search_apps", Description: "Find apps", Parameters: map[string]any{"type": "object"}}}
+	messages := []ToolMessage{
+		{Role: RoleUser, Content: "open slack"},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_0", Name: "search_apps", Arguments: `{"query":"sl"}`}}},
+		{Role: RoleTool, ToolCallID: "call_0", Content: "no apps found"},
+	}
+	reply, err := client.CallTools(context.Background(), messages, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ToolCall{{ID: "call_a", Name: "search_apps", Arguments: `{"query":"slack"}`}, {ID: "call_1", Name: "calculate", Arguments: "{}"}}
+	if reply.Role != RoleAssistant || len(reply.ToolCalls) != 2 || reply.ToolCalls[0] != want[0] || reply.ToolCalls[1] != want[1] {
+		t.Errorf("reply = %+v", reply)
+	}
+
+	sentTools := got["tools"].([]any)
+	function := sentTools[0].(map[string]any)["function"].(map[string]any)
+	if function["name"] != "search_apps" || got["tool_choice"] != "auto" {
+		t.Errorf("tools sent as %v", got["tools"])
+	}
+	sent := got["messages"].([]any)
+	if len(sent) != 4 || sent[0].(map[string]any)["role"] != RoleSystem {
+		t.Fatalf("messages sent as %v", sent)
+	}
+	call := sent[2].(map[string]any)["tool_calls"].([]any)[0].(map[string]any)
+	if call["id"] != "call_0" || call["type"] != "function" || sent[3].(map[string]any)["tool_call_id"] != "call_0" {
+		t.Errorf("tool call history sent as %v", sent)
+	}
+}
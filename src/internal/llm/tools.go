@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RoleTool is the role of a message carrying the result of a tool call.
+const RoleTool = "tool"
+
+// Tool is a function the model may ask to call. Parameters is a JSON schema object.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+// ToolCall is the model asking for a tool to be run. Arguments is a JSON object.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// ToolMessage is a message of a conversation that uses tools. Assistant messages may carry
+// ToolCalls instead of content, and RoleTool messages answer the call named by ToolCallID.
+type ToolMessage struct {
+	Role       string
+	Content    string
+	ToolCalls  []ToolCall
+	ToolCallID string
+}
+
+// ToolCaller is implemented by clients of models that support function calling.
+type ToolCaller interface {
+	CallTools(ctx context.Context, messages []ToolMessage, tools []Tool) (ToolMessage, error)
+}
+
+type wireFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+	Arguments   string         `json:"arguments,omitempty"`
+}
+
+type wireTool struct {
+	Type     string       `json:"type"`
+	Function wireFunction `json:"function"`
+}
+
+type wireToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function wireFunction `json:"function"`
+}
+
+type wireMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []wireToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type toolRequest struct {
+	Model       string        `json:"model"`
+	Messages    []wireMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	Tools       []wireTool    `json:"tools"`
+	ToolChoice  string        `json:"tool_choice"`
+}
+
+type toolResponse struct {
+	Choices []struct {
+		Message wireMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// CallTools sends the conversation with the tool definitions and returns the model's reply,
+// which either answers in Content or asks for ToolCalls.
+func (c *OpenAIClient) CallTools(ctx context.Context, messages []ToolMessage, tools []Tool) (ToolMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	request := toolRequest{Model: c.config.Model, Temperature: c.config.Temperature, ToolChoice: "auto"}
+	if c.config.SystemPrompt != "" {
+		request.Messages = append(request.Messages, wireMessage{Role: RoleSystem, Content: c.config.SystemPrompt})
+	}
+	for _, m := range messages {
+		wire := wireMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			wire.ToolCalls = append(wire.ToolCalls, wireToolCall{ID: call.ID, Type: "function", Function: wireFunction{Name: call.Name, Arguments: call.Arguments}})
+		}
+		request.Messages = append(request.Messages, wire)
+	}
+	for _, tool := range tools {
+		request.Tools = append(request.Tools, wireTool{Type: "function", Function: wireFunction{Name: tool.Name, Description: tool.Description, Parameters: tool.Parameters}})
+	}
+
+	resp, err := c.send(ctx, request, false)
+	if err != nil {
+		return ToolMessage{}, err
+	}
+	defer resp.Body.Close()
+
+	var parsed toolResponse
+	if err = json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return ToolMessage{}, fmt.Errorf("invalid response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return ToolMessage{}, errors.New("the model returned no answer")
+	}
+	reply := parsed.Choices[0].Message
+	message := ToolMessage{Role: RoleAssistant, Content: strings.TrimSpace(reply.Content)}
+	for i, call := range reply.ToolCalls {
+		id := call.ID
+		if id == "" {
+			// some local servers leave the id out, it only has to be unique within the reply
+			id = fmt.Sprintf("call_%d", i)
+		}
+		arguments := call.Function.Arguments
+		if arguments == "" {
+			arguments = "{}"
+		}
+		message.ToolCalls = append(message.ToolCalls, ToolCall{ID: id, Name: call.Function.Name, Arguments: arguments})
+	}
+	return message, nil
+}
@@ -0,0 +1,153 @@
+package opener
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Command is one thing for the OS to start.
+type Command struct {
+	Path string
+	Args []string
+	Dir  string
+	// Shell asks the OS shell (ShellExecute on Windows) to open Path with its associated program,
+	// rather than starting Path as an executable.
+	Shell bool
+}
+
+// Runner starts commands without waiting for them. Tests swap in one that records them instead.
+// The returned channel is closed when the started process ends, it is nil when that can't be
+// known, as for what the Windows shell opens.
+type Runner interface {
+	Start(cmd Command) (exited <-chan struct{}, err error)
+	LookPath(file string) (string, error)
+}
+
+// backend turns open requests into commands for one platform.
+type backend interface {
+	uri(uri string, runner Runner) (Command, error)
+	file(path string, runner Runner) (Command, error)
+	program(path string, args []string, dir string) Command
+}
+
+// Opener opens URIs, documents and programs.
+type Opener struct {
+	runner  Runner
+	backend backend
+}
+
+// New returns an opener for this platform that starts commands through runner.
+func New(runner Runner) *Opener {
+	return &Opener{runner: runner, backend: platformBackend()}
+}
+
+var defaultOpener = New(execRunner{})
+
+// OpenURI opens uri, such as a web page, in the user's preferred application.
+func OpenURI(uri string) error { return defaultOpener.OpenURI(uri) }
+
+// OpenFile opens a document with the application associated with its type.
+func OpenFile(path string) error { return defaultOpener.OpenFile(path) }
+
+// Launch starts an executable with args, in dir when it isn't empty. exited is closed when
+// the program ends.
+func Launch(path string, args []string, dir string) (exited <-chan struct{}, err error) {
+	return defaultOpener.Launch(path, args, dir)
+}
+
+func (o *Opener) OpenURI(uri string) error {
+	uri = strings.TrimSpace(uri)
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+	if parsed.Scheme == "" {
+		return fmt.Errorf("invalid URI %q: missing scheme", uri)
+	}
+	log.Printf("Opening URI: %s", uri)
+	cmd, err := o.backend.uri(uri, o.runner)
+	if err != nil {
+		return err
+	}
+	_, err = o.start(cmd)
+	return err
+}
+
+func (o *Opener) OpenFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s no longer exists", filepath.Base(path))
+		}
+		return err
+	}
+	cmd, err := o.backend.file(path, o.runner)
+	if err != nil {
+		return err
+	}
+	_, err = o.start(cmd)
+	return err
+}
+
+func (o *Opener) Launch(path string, args []string, dir string) (<-chan struct{}, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("no program to start")
+	}
+	return o.start(o.backend.program(path, args, dir))
+}
+
+func (o *Opener) start(cmd Command) (<-chan struct{}, error) {
+	exited, err := o.runner.Start(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", cmd.Path, err)
+	}
+	return exited, nil
+}
+
+// shellBackend hands URIs and files to the Windows shell.
+type shellBackend struct{}
+
+func (shellBackend) uri(uri string, _ Runner) (Command, error) {
+	return Command{Path: uri, Shell: true}, nil
+}
+
+func (shellBackend) file(path string, _ Runner) (Command, error) {
+	return Command{Path: path, Dir: filepath.Dir(path), Shell: true}, nil
+}
+
+// Programs get their own folder as working directory unless told otherwise, like Start Menu shortcuts do.
+func (shellBackend) program(path string, args []string, dir string) Command {
+	if dir == "" && filepath.IsAbs(path) {
+		dir = filepath.Dir(path)
+	}
+	return Command{Path: path, Args: args, Dir: dir}
+}
+
+// xdgBackend opens URIs and files with xdg-open, or GLib's gio where xdg-utils isn't installed.
+type xdgBackend struct{}
+
+func (b xdgBackend) uri(uri string, runner Runner) (Command, error) {
+	return b.open(uri, runner)
+}
+
+func (b xdgBackend) file(path string, runner Runner) (Command, error) {
+	return b.open(path, runner)
+}
+
+func (xdgBackend) open(target string, runner Runner) (Command, error) {
+	if path, err := runner.LookPath("xdg-open"); err == nil {
+		return Command{Path: path, Args: []string{target}}, nil
+	}
+	if path, err := runner.LookPath("gio"); err == nil {
+		return Command{Path: path, Args: []string{"open", target}}, nil
+	}
+	return Command{}, errors.New("neither xdg-open nor gio is installed")
+}
+
+func (xdgBackend) program(path string, args []string, dir string) Command {
+	return Command{Path: path, Args: args, Dir: dir}
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package opener
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func platformBackend() backend { return xdgBackend{} }
+
+type execRunner struct{}
+
+// Start detaches the child into its own session so closing the launcher doesn't take it along.
+func (execRunner) Start(cmd Command) (<-chan struct{}, error) {
+	c := exec.Command(cmd.Path, cmd.Args...)
+	c.Dir = cmd.Dir
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	// reap it so finished xdg-open calls don't linger as zombies
+	exited := make(chan struct{})
+	go func() {
+		_ = c.Wait()
+		close(exited)
+	}()
+	return exited, nil
+}
+
+func (execRunner) LookPath(file string) (string, error) { return exec.LookPath(file) }
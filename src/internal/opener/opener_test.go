@@ -0,0 +1,122 @@
+package opener
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// recorder is a Runner that remembers commands instead of starting them.
+type recorder struct {
+	installed []string
+	started   []Command
+	err       error
+}
+
+func (r *recorder) Start(cmd Command) (<-chan struct{}, error) {
+	r.started = append(r.started, cmd)
+	return nil, r.err
+}
+
+func (r *recorder) LookPath(file string) (string, error) {
+	if slices.Contains(r.installed, file) {
+		return "/usr/bin/" + file, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (r *recorder) last(t *testing.T) Command {
+	t.Helper()
+	if len(r.started) != 1 {
+		t.Fatalf("expected one command, got %+v", r.started)
+	}
+	return r.started[0]
+}
+
+func sameCommand(a, b Command) bool {
+	return a.Path == b.Path && slices.Equal(a.Args, b.Args) && a.Dir == b.Dir && a.Shell == b.Shell
+}
+
+func TestXDGBackendPrefersXDGOpen(t *testing.T) {
+	r := &recorder{installed: []string{"xdg-open", "gio"}}
+	o := &Opener{runner: r, backend: xdgBackend{}}
+	if err := o.OpenURI(" https://example.com/?q=a+b "); err != nil {
+		t.Fatal(err)
+	}
+	want := Command{Path: "/usr/bin/xdg-open", Args: []string{"https://example.com/?q=a+b"}}
+	if got := r.last(t); !sameCommand(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestXDGBackendFallsBackToGio(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{installed: []string{"gio"}}
+	o := &Opener{runner: r, backend: xdgBackend{}}
+	if err := o.OpenFile(file); err != nil {
+		t.Fatal(err)
+	}
+	want := Command{Path: "/usr/bin/gio", Args: []string{"open", file}}
+	if got := r.last(t); !sameCommand(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if err := (&Opener{runner: &recorder{}, backend: xdgBackend{}}).OpenFile(file); err == nil {
+		t.Fatal("expected an error when no opener is installed")
+	}
+}
+
+func TestShellBackend(t *testing.T) {
+	r := &recorder{}
+	o := &Opener{runner: r, backend: shellBackend{}}
+	if err := o.OpenURI("https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.last(t); !sameCommand(got, Command{Path: "https://example.com", Shell: true}) {
+		t.Fatalf("unexpected URI command %+v", got)
+	}
+
+	program := filepath.Join(string(filepath.Separator)+"apps", "editor", "editor.exe")
+	r.started = nil
+	if _, err := o.Launch(program, []string{"--new"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.last(t); !sameCommand(got, Command{Path: program, Args: []string{"--new"}, Dir: filepath.Dir(program)}) {
+		t.Fatalf("unexpected program command %+v", got)
+	}
+
+	r.started = nil
+	if _, err := o.Launch("calc.exe", nil, `C:\work`); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.last(t); !sameCommand(got, Command{Path: "calc.exe", Dir: `C:\work`}) {
+		t.Fatalf("unexpected program command %+v", got)
+	}
+}
+
+func TestOpenerReportsErrors(t *testing.T) {
+	r := &recorder{installed: []string{"xdg-open"}, err: errors.New("boom")}
+	o := &Opener{runner: r, backend: xdgBackend{}}
+
+	if err := o.OpenURI("https://example.com"); err == nil || !errors.Is(err, r.err) {
+		t.Errorf("runner error should be wrapped, got %v", err)
+	}
+	if err := o.OpenURI("example.com"); err == nil {
+		t.Error("URI without a scheme should fail")
+	}
+	if err := o.OpenFile(filepath.Join(t.TempDir(), "missing.pdf")); err == nil {
+		t.Error("missing file should fail")
+	}
+	if _, err := o.Launch(" ", nil, ""); err == nil {
+		t.Error("empty program should fail")
+	}
+	if len(r.started) != 1 {
+		t.Errorf("only the valid URI should have been started, got %+v", r.started)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package opener
+
+import (
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+func platformBackend() backend { return shellBackend{} }
+
+type execRunner struct{}
+
+func (execRunner) Start(cmd Command) (<-chan struct{}, error) {
+	if cmd.Shell {
+		return nil, shellExecute(cmd)
+	}
+	c := exec.Command(cmd.Path, cmd.Args...)
+	c.Dir = cmd.Dir
+	c.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow: true,
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	// waiting also releases the process handle
+	exited := make(chan struct{})
+	go func() {
+		_ = c.Wait()
+		close(exited)
+	}()
+	return exited, nil
+}
+
+func (execRunner) LookPath(file string) (string, error) { return exec.LookPath(file) }
+
+func shellExecute(cmd Command) error {
+	verb, err := windows.UTF16PtrFromString("open")
+	if err != nil {
+		return err
+	}
+	file, err := windows.UTF16PtrFromString(cmd.Path)
+	if err != nil {
+		return err
+	}
+	var args, dir *uint16
+	if len(cmd.Args) > 0 {
+		if args, err = windows.UTF16PtrFromString(windows.ComposeCommandLine(cmd.Args)); err != nil {
+			return err
+		}
+	}
+	if cmd.Dir != "" {
+		if dir, err = windows.UTF16PtrFromString(cmd.Dir); err != nil {
+			return err
+		}
+	}
+	return windows.ShellExecute(0, verb, file, args, dir, windows.SW_SHOWNORMAL)
+}
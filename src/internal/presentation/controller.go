@@ -1,6 +1,11 @@
 package presentation
 
-import "sync"
+import (
+	"image"
+	"slices"
+	"sync"
+	"time"
+)
 
 type Page uint8
 
@@ -10,6 +15,12 @@ const (
 	PageHelp
 	PageSettings
 	PageAbout
+	PageEngines
+	PageIndexing
+	PageHotkeys
+	PageLLM
+	PageTemplates
+	PageBlocklist
 )
 
 type CommandKind uint8
@@ -20,25 +31,76 @@ const (
 	CommandSetMode
 	CommandSetQuery
 	CommandSetMessage
+	CommandAppendMessage
 	CommandSetLoading
 	CommandSetPage
 	CommandSetResults
 	CommandSelectResult
 	CommandFocusSearch
 	CommandFocusHandled
+	CommandOpenDialog
+	CommandCloseDialog
+)
+
+type ResultKind uint8
+
+const (
+	ResultApp ResultKind = iota
+	ResultDocument
+	ResultWindow
+	ResultChat
+)
+
+// Result is a row of the results list.
+type Result struct {
+	Kind  ResultKind
+	Title string
+	// Target is what opening the result acts on: the app's command line, the document's path or the chat's id.
+	Target string
+	// Detail describes the result further, like the folder of a document or the process owning a window.
+	Detail string
+	// Matches are the indexes of the runes of Title that matched the query.
+	Matches []int
+	// Window is the handle of a window result and Number the shortcut typed to pick it.
+	Window uint64
+	Number int
+	Icon   image.Image `json:"-"`
+}
+
+type DialogKind uint8
+
+const (
+	DialogNone DialogKind = iota
+	// DialogSendPrompt holds a Quick GPT prompt with attached context, the prompt as Payload.
+	DialogSendPrompt
+	// DialogRunAction holds what a natural-language command decided to do, an agent.Action.
+	DialogRunAction
+	// DialogKillProcess holds the window Result whose process would be ended.
+	DialogKillProcess
+	// DialogClearBlocklist asks before unblocking every app.
+	DialogClearBlocklist
 )
 
+// Dialog is a question waiting for the user to confirm or cancel it.
+type Dialog struct {
+	Kind    DialogKind
+	Payload any
+}
+
 type Command struct {
-	Kind        CommandKind
-	Mode        int
-	Query       string
-	Message     string
-	Loading     bool
-	Page        Page
-	ResultCount int
-	Selected    int
+	Kind     CommandKind
+	Mode     int
+	Query    string
+	Message  string
+	Loading  bool
+	Page     Page
+	Results  []Result
+	Selected int
+	Dialog   Dialog
 }
 
+// State is everything the launcher shows. Results is replaced as a whole and never changed
+// in place, so snapshots can share it.
 type State struct {
 	Visible     bool
 	Mode        int
@@ -46,34 +108,70 @@ type State struct {
 	Message     string
 	Loading     bool
 	Page        Page
-	ResultCount int
+	Results     []Result
 	Selected    int
 	FocusSearch bool
+	Dialog      Dialog
 }
 
+// Field names a part of State, as a bit in Change.Fields.
+type Field uint16
+
+const (
+	FieldVisible Field = 1 << iota
+	FieldMode
+	FieldQuery
+	FieldMessage
+	FieldLoading
+	FieldPage
+	FieldResults
+	FieldSelected
+	FieldFocusSearch
+	FieldDialog
+)
+
+// Change is what a command, or a batch of them, did to the state. Results counts as changed
+// whenever it was replaced.
+type Change struct {
+	Before, After State
+	Fields        Field
+}
+
+// Has reports whether any of fields changed.
+func (c Change) Has(fields Field) bool { return c.Fields&fields != 0 }
+
 type queuedCommand struct {
-	command  Command
+	commands []Command
 	response chan State
 }
 
 type Controller struct {
-	mu         sync.RWMutex
-	state      State
-	commands   chan queuedCommand
-	done       chan struct{}
-	stopOnce   sync.Once
-	invalidate func()
+	mu           sync.RWMutex
+	state        State
+	commands     chan queuedCommand
+	done         chan struct{}
+	stopOnce     sync.Once
+	invalidate   func()
+	observers    map[int]func(Change)
+	nextObserver int
+	history      history
 }
 
 func NewController(initialMode int) *Controller {
+	return NewControllerFrom(State{
+		Mode:     initialMode,
+		Page:     PageLauncher,
+		Selected: -1,
+	})
+}
+
+// NewControllerFrom returns a controller starting in state, like one recorded in a Log.
+func NewControllerFrom(state State) *Controller {
 	c := &Controller{
-		state: State{
-			Mode:     initialMode,
-			Page:     PageLauncher,
-			Selected: -1,
-		},
+		state:    state,
 		commands: make(chan queuedCommand, 32),
 		done:     make(chan struct{}),
+		history:  history{start: state},
 	}
 	go c.run()
 	return c
@@ -86,12 +184,18 @@ func (c *Controller) Close() {
 }
 
 func (c *Controller) Post(command Command) bool {
-	return c.enqueue(queuedCommand{command: command})
+	return c.enqueue(queuedCommand{commands: []Command{command}})
 }
 
 func (c *Controller) Dispatch(command Command) (State, bool) {
+	return c.Batch(command)
+}
+
+// Batch applies commands in order as one change: frames and observers only see the state
+// after the last one, and the view is invalidated once.
+func (c *Controller) Batch(commands ...Command) (State, bool) {
 	response := make(chan State, 1)
-	if !c.enqueue(queuedCommand{command: command, response: response}) {
+	if !c.enqueue(queuedCommand{commands: commands, response: response}) {
 		return State{}, false
 	}
 
@@ -115,6 +219,25 @@ func (c *Controller) SetInvalidator(invalidate func()) {
 	c.mu.Unlock()
 }
 
+// Subscribe calls observer with every change until unsubscribe is called. Observers run in
+// order on the controller's goroutine before Dispatch or Batch return, so they must return
+// quickly and must not Dispatch or Batch themselves; Post is fine.
+func (c *Controller) Subscribe(observer func(Change)) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.observers == nil {
+		c.observers = make(map[int]func(Change))
+	}
+	id := c.nextObserver
+	c.nextObserver++
+	c.observers[id] = observer
+	return func() {
+		c.mu.Lock()
+		delete(c.observers, id)
+		c.mu.Unlock()
+	}
+}
+
 func (c *Controller) enqueue(command queuedCommand) bool {
 	select {
 	case <-c.done:
@@ -130,42 +253,81 @@ func (c *Controller) run() {
 		case <-c.done:
 			return
 		case queued := <-c.commands:
-			state, invalidate := c.apply(queued.command)
+			change, invalidate, observers := c.apply(queued.commands)
+			// observers see a change before Dispatch returns it
+			if change.Fields != 0 {
+				for _, observer := range observers {
+					observer(change)
+				}
+			}
 			if queued.response != nil {
-				queued.response <- state
+				queued.response <- change.After
 			}
-			if invalidate != nil {
+			if change.Fields != 0 && invalidate != nil {
 				invalidate()
 			}
 		}
 	}
 }
 
-func (c *Controller) apply(command Command) (State, func()) {
+func (c *Controller) apply(commands []Command) (Change, func(), []func(Change)) {
 	c.mu.Lock()
+	defer c.mu.Unlock()
+	change := Change{Before: c.state}
+	now := time.Now()
+	c.history.batch++
+	for _, command := range commands {
+		change.Fields |= c.reduce(command)
+		// the caller may reuse its slice, the state's copy never changes
+		if command.Kind == CommandSetResults {
+			command.Results = c.state.Results
+		}
+		c.history.add(Entry{Time: now, Batch: c.history.batch, Command: command, State: c.state})
+	}
+	change.After = c.state
+	observers := make([]func(Change), 0, len(c.observers))
+	for id := range c.nextObserver {
+		if observer, ok := c.observers[id]; ok {
+			observers = append(observers, observer)
+		}
+	}
+	return change, c.invalidate, observers
+}
+
+// reduce applies command to the state and returns the fields it changed.
+func (c *Controller) reduce(command Command) Field {
+	before := c.state
+	replacedResults := false
 	switch command.Kind {
 	case CommandShow:
 		c.state.Visible = true
 	case CommandHide:
 		c.state.Visible = false
 	case CommandSetMode:
+		// results and questions belong to the mode they came from
+		replacedResults = len(c.state.Results) > 0
 		c.state.Mode = command.Mode
+		c.state.Results = nil
 		c.state.Selected = -1
+		c.state.Dialog = Dialog{}
 	case CommandSetQuery:
 		c.state.Query = command.Query
 		c.state.Selected = -1
 	case CommandSetMessage:
 		c.state.Message = command.Message
+	case CommandAppendMessage:
+		c.state.Message += command.Message
 	case CommandSetLoading:
 		c.state.Loading = command.Loading
 	case CommandSetPage:
 		c.state.Page = command.Page
 		c.state.FocusSearch = command.Page == PageLauncher
 	case CommandSetResults:
-		c.state.ResultCount = max(command.ResultCount, 0)
+		replacedResults = len(c.state.Results) > 0 || len(command.Results) > 0
+		c.state.Results = slices.Clone(command.Results)
 		c.state.Selected = -1
 	case CommandSelectResult:
-		if command.Selected >= 0 && command.Selected < c.state.ResultCount {
+		if command.Selected >= 0 && command.Selected < len(c.state.Results) {
 			c.state.Selected = command.Selected
 		} else {
 			c.state.Selected = -1
@@ -174,9 +336,28 @@ func (c *Controller) apply(command Command) (State, func()) {
 		c.state.FocusSearch = true
 	case CommandFocusHandled:
 		c.state.FocusSearch = false
+	case CommandOpenDialog:
+		c.state.Dialog = command.Dialog
+	case CommandCloseDialog:
+		c.state.Dialog = Dialog{}
 	}
-	state := c.state
-	invalidate := c.invalidate
-	c.mu.Unlock()
-	return state, invalidate
+
+	var fields Field
+	mark := func(field Field, changed bool) {
+		if changed {
+			fields |= field
+		}
+	}
+	mark(FieldVisible, before.Visible != c.state.Visible)
+	mark(FieldMode, before.Mode != c.state.Mode)
+	mark(FieldQuery, before.Query != c.state.Query)
+	mark(FieldMessage, before.Message != c.state.Message)
+	mark(FieldLoading, before.Loading != c.state.Loading)
+	mark(FieldPage, before.Page != c.state.Page)
+	mark(FieldResults, replacedResults)
+	mark(FieldSelected, before.Selected != c.state.Selected)
+	mark(FieldFocusSearch, before.FocusSearch != c.state.FocusSearch)
+	// payloads aren't always comparable, opening a dialog always counts
+	mark(FieldDialog, command.Kind == CommandOpenDialog || before.Dialog.Kind != c.state.Dialog.Kind)
+	return fields
 }
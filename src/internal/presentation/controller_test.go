@@ -11,11 +11,11 @@ func TestControllerUpdatesStateAndInvalidates(t *testing.T) {
 		invalidated <- struct{}{}
 	})
 
-	state, ok := controller.Dispatch(Command{Kind: CommandSetResults, ResultCount: 3})
+	state, ok := controller.Dispatch(Command{Kind: CommandSetResults, Results: make([]Result, 3)})
 	if !ok {
 		t.Fatal("controller stopped unexpectedly")
 	}
-	if state.ResultCount != 3 || state.Selected != -1 {
+	if len(state.Results) != 3 || state.Selected != -1 {
 		t.Fatalf("unexpected result state: %+v", state)
 	}
 
@@ -35,7 +35,7 @@ func TestControllerClearsSelectionWhenQueryChanges(t *testing.T) {
 	controller := NewController(10)
 	t.Cleanup(controller.Close)
 
-	_, _ = controller.Dispatch(Command{Kind: CommandSetResults, ResultCount: 2})
+	_, _ = controller.Dispatch(Command{Kind: CommandSetResults, Results: make([]Result, 2)})
 	_, _ = controller.Dispatch(Command{Kind: CommandSelectResult, Selected: 0})
 	state, ok := controller.Dispatch(Command{Kind: CommandSetQuery, Query: "calc"})
 	if !ok {
@@ -45,3 +45,95 @@ func TestControllerClearsSelectionWhenQueryChanges(t *testing.T) {
 		t.Fatalf("unexpected query state: %+v", state)
 	}
 }
+
+func TestControllerAppendsToMessage(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	_, _ = controller.Dispatch(Command{Kind: CommandSetMessage, Message: "Hel"})
+	controller.Post(Command{Kind: CommandAppendMessage, Message: "lo"})
+	state, ok := controller.Dispatch(Command{Kind: CommandAppendMessage, Message: ", world"})
+	if !ok || state.Message != "Hello, world" {
+		t.Fatalf("unexpected message state: %+v", state)
+	}
+}
+
+func TestControllerModeChangeDropsResultsAndDialog(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	_, _ = controller.Dispatch(Command{Kind: CommandSetResults, Results: []Result{{Kind: ResultWindow, Title: "Editor", Window: 7}}})
+	_, _ = controller.Dispatch(Command{Kind: CommandSelectResult, Selected: 0})
+	state, _ := controller.Dispatch(Command{Kind: CommandOpenDialog, Dialog: Dialog{Kind: DialogKillProcess, Payload: Result{Window: 7}}})
+	if state.Dialog.Kind != DialogKillProcess || state.Selected != 0 {
+		t.Fatalf("unexpected dialog state: %+v", state)
+	}
+
+	state, _ = controller.Dispatch(Command{Kind: CommandSetMode, Mode: 21})
+	if state.Mode != 21 || state.Results != nil || state.Selected != -1 || state.Dialog.Kind != DialogNone {
+		t.Fatalf("unexpected state after changing mode: %+v", state)
+	}
+}
+
+func TestControllerSnapshotsDontShareResults(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	results := []Result{{Title: "a"}, {Title: "b"}}
+	state, _ := controller.Dispatch(Command{Kind: CommandSetResults, Results: results})
+	results[0].Title = "changed"
+	if state.Results[0].Title != "a" || controller.Snapshot().Results[0].Title != "a" {
+		t.Fatal("the controller kept the caller's slice")
+	}
+}
+
+func TestControllerBatchIsOneChange(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	var invalidations int
+	controller.SetInvalidator(func() { invalidations++ })
+	var changes []Change
+	controller.Subscribe(func(change Change) { changes = append(changes, change) })
+
+	state, ok := controller.Batch(
+		Command{Kind: CommandShow},
+		Command{Kind: CommandSetMode, Mode: 21},
+		Command{Kind: CommandSetQuery, Query: "notes"},
+		Command{Kind: CommandSetResults, Results: make([]Result, 2)},
+		Command{Kind: CommandSelectResult, Selected: 1},
+	)
+	if !ok || !state.Visible || state.Mode != 21 || state.Query != "notes" || state.Selected != 1 {
+		t.Fatalf("unexpected state after batch: %+v", state)
+	}
+	// the view is invalidated after Batch returns, once the next command is taken it was
+	_, _ = controller.Dispatch(Command{Kind: CommandFocusHandled})
+	if invalidations != 1 || len(changes) != 1 {
+		t.Fatalf("batch invalidated %d times and notified %d times", invalidations, len(changes))
+	}
+	change := changes[0]
+	want := FieldVisible | FieldMode | FieldQuery | FieldResults | FieldSelected
+	if change.Fields != want || change.Before.Visible || change.After.Query != "notes" {
+		t.Errorf("unexpected change: fields %b, want %b", change.Fields, want)
+	}
+}
+
+func TestControllerOnlyReportsChanges(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	var changes []Change
+	unsubscribe := controller.Subscribe(func(change Change) { changes = append(changes, change) })
+
+	_, _ = controller.Dispatch(Command{Kind: CommandSetMessage, Message: "hello"})
+	_, _ = controller.Dispatch(Command{Kind: CommandSetMessage, Message: "hello"})
+	_, _ = controller.Dispatch(Command{Kind: CommandSetResults})
+	_, _ = controller.Dispatch(Command{Kind: CommandOpenDialog, Dialog: Dialog{Kind: DialogClearBlocklist}})
+	unsubscribe()
+	_, _ = controller.Dispatch(Command{Kind: CommandHide})
+	_, _ = controller.Dispatch(Command{Kind: CommandShow})
+
+	if len(changes) != 2 || changes[0].Fields != FieldMessage || !changes[1].Has(FieldDialog) || changes[1].Has(FieldMessage) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
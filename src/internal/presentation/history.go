@@ -0,0 +1,190 @@
+package presentation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// HistorySize is how many commands a controller remembers.
+const HistorySize = 500
+
+// Entry is a command the controller applied and the state it left.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Batch is shared by the commands applied together.
+	Batch   uint64  `json:"batch"`
+	Command Command `json:"command"`
+	State   State   `json:"state"`
+}
+
+// Log is the commands a controller remembers, oldest first, and the state before the first one.
+type Log struct {
+	Start   State   `json:"start"`
+	Entries []Entry `json:"entries"`
+}
+
+// history is a ring of the last HistorySize entries.
+type history struct {
+	start   State
+	entries []Entry
+	oldest  int
+	batch   uint64
+}
+
+func (h *history) add(entry Entry) {
+	if len(h.entries) < HistorySize {
+		h.entries = append(h.entries, entry)
+		return
+	}
+	h.start = h.entries[h.oldest].State
+	h.entries[h.oldest] = entry
+	h.oldest = (h.oldest + 1) % len(h.entries)
+}
+
+func (h *history) log() Log {
+	entries := make([]Entry, 0, len(h.entries))
+	entries = append(entries, h.entries[h.oldest:]...)
+	entries = append(entries, h.entries[:h.oldest]...)
+	return Log{Start: h.start, Entries: entries}
+}
+
+// History returns the commands the controller remembers.
+func (c *Controller) History() Log {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.history.log()
+}
+
+// WriteHistory saves the commands the controller remembers as JSON, for ReadLog.
+func (c *Controller) WriteHistory(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.History())
+}
+
+// ReadLog reads what WriteHistory saved. Dialog payloads come back as plain JSON values.
+func ReadLog(r io.Reader) (Log, error) {
+	var log Log
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return Log{}, fmt.Errorf("invalid command log: %w", err)
+	}
+	return log, nil
+}
+
+// Step is a replayed entry and the state the command produced this time.
+type Step struct {
+	Entry Entry
+	State State
+	// Matches is false when the controller no longer ends up where it did when recording.
+	Matches bool
+}
+
+// Replay feeds the logged commands to a fresh controller starting from the logged state.
+func Replay(log Log) []Step {
+	controller := NewControllerFrom(log.Start)
+	defer controller.Close()
+	steps := make([]Step, 0, len(log.Entries))
+	for _, entry := range log.Entries {
+		state, _ := controller.Dispatch(entry.Command)
+		steps = append(steps, Step{Entry: entry, State: state, Matches: sameState(state, entry.State)})
+	}
+	return steps
+}
+
+// sameState compares states the way they're logged, since icons and dialog payloads aren't comparable.
+func sameState(a, b State) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+var commandNames = [...]string{
+	CommandShow:          "Show",
+	CommandHide:          "Hide",
+	CommandSetMode:       "SetMode",
+	CommandSetQuery:      "SetQuery",
+	CommandSetMessage:    "SetMessage",
+	CommandAppendMessage: "AppendMessage",
+	CommandSetLoading:    "SetLoading",
+	CommandSetPage:       "SetPage",
+	CommandSetResults:    "SetResults",
+	CommandSelectResult:  "SelectResult",
+	CommandFocusSearch:   "FocusSearch",
+	CommandFocusHandled:  "FocusHandled",
+	CommandOpenDialog:    "OpenDialog",
+	CommandCloseDialog:   "CloseDialog",
+}
+
+func (k CommandKind) String() string {
+	if int(k) < len(commandNames) {
+		return commandNames[k]
+	}
+	return "Command(" + strconv.Itoa(int(k)) + ")"
+}
+
+var pageNames = [...]string{
+	PageLauncher:  "Launcher",
+	PageMenu:      "Menu",
+	PageHelp:      "Help",
+	PageSettings:  "Settings",
+	PageAbout:     "About",
+	PageEngines:   "Search engines",
+	PageIndexing:  "Document indexing",
+	PageHotkeys:   "Hotkeys",
+	PageLLM:       "Quick GPT",
+	PageTemplates: "Prompt templates",
+	PageBlocklist: "Blocklist",
+}
+
+func (p Page) String() string {
+	if int(p) < len(pageNames) {
+		return pageNames[p]
+	}
+	return "Page(" + strconv.Itoa(int(p)) + ")"
+}
+
+var dialogNames = [...]string{
+	DialogNone:           "none",
+	DialogSendPrompt:     "send prompt",
+	DialogRunAction:      "run action",
+	DialogKillProcess:    "kill process",
+	DialogClearBlocklist: "clear blocklist",
+}
+
+func (k DialogKind) String() string {
+	if int(k) < len(dialogNames) {
+		return dialogNames[k]
+	}
+	return "Dialog(" + strconv.Itoa(int(k)) + ")"
+}
+
+// String describes the command with the argument its kind uses.
+func (c Command) String() string {
+	switch c.Kind {
+	case CommandSetMode:
+		return fmt.Sprintf("%s %d", c.Kind, c.Mode)
+	case CommandSetQuery, CommandSetMessage, CommandAppendMessage:
+		text := c.Query
+		if c.Kind != CommandSetQuery {
+			text = c.Message
+		}
+		if runes := []rune(text); len(runes) > 40 {
+			text = string(runes[:40]) + "..."
+		}
+		return fmt.Sprintf("%s %q", c.Kind, text)
+	case CommandSetLoading:
+		return fmt.Sprintf("%s %t", c.Kind, c.Loading)
+	case CommandSetPage:
+		return fmt.Sprintf("%s %s", c.Kind, c.Page)
+	case CommandSetResults:
+		return fmt.Sprintf("%s (%d)", c.Kind, len(c.Results))
+	case CommandSelectResult:
+		return fmt.Sprintf("%s %d", c.Kind, c.Selected)
+	case CommandOpenDialog:
+		return fmt.Sprintf("%s %s", c.Kind, c.Dialog.Kind)
+	}
+	return c.Kind.String()
+}
@@ -0,0 +1,81 @@
+package presentation
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestHistoryKeepsTheLastCommands(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	for i := range HistorySize + 5 {
+		controller.Dispatch(Command{Kind: CommandSetQuery, Query: fmt.Sprintf("q%d", i)})
+	}
+	controller.Batch(Command{Kind: CommandShow}, Command{Kind: CommandSetMessage, Message: "both"})
+
+	log := controller.History()
+	if len(log.Entries) != HistorySize {
+		t.Fatalf("kept %d entries", len(log.Entries))
+	}
+	if log.Start.Query != "q6" || log.Entries[0].Command.Query != "q7" {
+		t.Errorf("log starts at %q, first command %v", log.Start.Query, log.Entries[0].Command)
+	}
+	last, beforeLast := log.Entries[len(log.Entries)-1], log.Entries[len(log.Entries)-2]
+	if last.Batch != beforeLast.Batch || last.State.Message != "both" || !beforeLast.State.Visible || beforeLast.State.Message != "" {
+		t.Errorf("batch entries: %+v, %+v", beforeLast, last)
+	}
+}
+
+func TestReplayReproducesRecordedStates(t *testing.T) {
+	controller := NewController(10)
+	t.Cleanup(controller.Close)
+
+	controller.Dispatch(Command{Kind: CommandSetQuery, Query: "ed"})
+	controller.Batch(
+		Command{Kind: CommandSetResults, Results: []Result{{Kind: ResultWindow, Title: "Editor", Window: 7}, {Title: "Terminal"}}},
+		Command{Kind: CommandSelectResult, Selected: 1},
+	)
+	controller.Dispatch(Command{Kind: CommandOpenDialog, Dialog: Dialog{Kind: DialogKillProcess, Payload: Result{Title: "Editor", Window: 7}}})
+	controller.Dispatch(Command{Kind: CommandAppendMessage, Message: "Press Enter"})
+
+	var saved bytes.Buffer
+	if err := controller.WriteHistory(&saved); err != nil {
+		t.Fatal(err)
+	}
+	log, err := ReadLog(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := Replay(log)
+	if len(steps) != 5 {
+		t.Fatalf("replayed %d steps", len(steps))
+	}
+	for _, step := range steps {
+		if !step.Matches {
+			t.Errorf("%v ended in %+v, recorded %+v", step.Entry.Command, step.State, step.Entry.State)
+		}
+	}
+
+	// a log recorded by a controller that behaved differently
+	log.Entries[2].State.Selected = 0
+	if steps = Replay(log); steps[2].Matches || !steps[3].Matches {
+		t.Errorf("replay didn't spot the difference: %+v", steps[2])
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	for command, want := range map[*Command]string{
+		{Kind: CommandSetPage, Page: PageBlocklist}:                      "SetPage Blocklist",
+		{Kind: CommandSetResults, Results: make([]Result, 3)}:            "SetResults (3)",
+		{Kind: CommandOpenDialog, Dialog: Dialog{Kind: DialogRunAction}}: "OpenDialog run action",
+		{Kind: CommandSetQuery, Query: "report"}:                         `SetQuery "report"`,
+		{Kind: CommandHide}:                                              "Hide",
+		{Kind: CommandKind(99)}:                                          "Command(99)",
+	} {
+		if got := command.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package prompts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"winfastnav/internal/settings"
+)
+
+// Source is context a template can attach to the prompt.
+type Source string
+
+const (
+	// SourceClipboard is the text on the clipboard.
+	SourceClipboard Source = "clipboard"
+	// SourceDocument is the text of the document result selected last.
+	SourceDocument Source = "document"
+	// SourceWindow is the title of the window that was active before the launcher.
+	SourceWindow Source = "window"
+)
+
+var sources = []Source{SourceClipboard, SourceDocument, SourceWindow}
+
+// Template is a saved prompt used as ":g <name> <text>". The prompt can contain {text}
+// for what follows the name, and {clipboard}, {document} or {window} to attach context.
+type Template struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+var defaultTemplates = []Template{
+	{Name: "tr", Prompt: "Translate the following text to English. Reply with the translation only.\n\n{text}"},
+	{Name: "fix", Prompt: "Fix the grammar and spelling of the following text, keeping its meaning and tone. Reply with the corrected text only.\n\n{text}"},
+	{Name: "err", Prompt: "Explain this error and how to fix it:\n\n{text}"},
+	{Name: "clip", Prompt: "{text}\n\n{clipboard}"},
+	{Name: "doc", Prompt: "{text}\n\n{document}"},
+	{Name: "win", Prompt: "{text}\n\nI'm working in the window titled \"{window}\"."},
+}
+
+var placeholder = regexp.MustCompile(`\{(\w+)\}`)
+
+var (
+	templatesMu sync.RWMutex
+	templates   []Template
+	loadOnce    sync.Once
+)
+
+// Templates returns the saved templates, or the defaults before any were saved.
+func Templates() []Template {
+	loadOnce.Do(load)
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	return slices.Clone(templates)
+}
+
+// SetTemplates validates and saves the templates.
+func SetTemplates(list []Template) error {
+	for i := range list {
+		list[i].Name = strings.TrimSpace(list[i].Name)
+		list[i].Prompt = strings.TrimSpace(list[i].Prompt)
+	}
+	if err := Validate(list); err != nil {
+		return err
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	if err = settings.SetSetting("prompttemplates", string(data)); err != nil {
+		return err
+	}
+	loadOnce.Do(func() {})
+	templatesMu.Lock()
+	templates = slices.Clone(list)
+	templatesMu.Unlock()
+	return nil
+}
+
+func load() {
+	list := slices.Clone(defaultTemplates)
+	if unparsed, err := settings.GetSetting("prompttemplates"); err == nil && unparsed != "" {
+		var saved []Template
+		if err = json.Unmarshal([]byte(unparsed), &saved); err != nil {
+			log.Printf("Error parsing prompt templates: %v", err)
+		} else {
+			list = saved
+		}
+	}
+	templatesMu.Lock()
+	templates = list
+	templatesMu.Unlock()
+}
+
+// Validate reports templates without a usable name, with duplicate names, or with unknown placeholders.
+func Validate(list []Template) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, t := range list {
+		switch {
+		case t.Name == "" || strings.ContainsAny(t.Name, " \t"):
+			errs = append(errs, fmt.Errorf("template name %q must be a single word", t.Name))
+		case seen[strings.ToLower(t.Name)]:
+			errs = append(errs, fmt.Errorf("template %q is defined twice", t.Name))
+		}
+		seen[strings.ToLower(t.Name)] = true
+		if t.Prompt == "" {
+			errs = append(errs, fmt.Errorf("template %q has no prompt", t.Name))
+		}
+		for _, match := range placeholder.FindAllStringSubmatch(t.Prompt, -1) {
+			if name := match[1]; name != "text" && !slices.Contains(sources, Source(name)) {
+				errs = append(errs, fmt.Errorf("template %q uses unknown placeholder {%s}", t.Name, name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Match finds the template named by the first word of input, returning it and the rest of the input.
+func Match(input string) (Template, string, bool) {
+	name, text, _ := strings.Cut(strings.TrimSpace(input), " ")
+	for _, t := range Templates() {
+		if strings.EqualFold(t.Name, name) {
+			return t, strings.TrimSpace(text), true
+		}
+	}
+	return Template{}, "", false
+}
+
+// Sources lists the context the template attaches, in the order of the placeholders.
+func (t Template) Sources() []Source {
+	var needed []Source
+	for _, match := range placeholder.FindAllStringSubmatch(t.Prompt, -1) {
+		source := Source(match[1])
+		if slices.Contains(sources, source) && !slices.Contains(needed, source) {
+			needed = append(needed, source)
+		}
+	}
+	return needed
+}
+
+// Expand fills in the placeholders. When the prompt has no {text}, the text is added at the end.
+func (t Template) Expand(text string, context map[Source]string) string {
+	prompt := t.Prompt
+	if text != "" && !strings.Contains(prompt, "{text}") {
+		prompt += "\n\n{text}"
+	}
+	prompt = placeholder.ReplaceAllStringFunc(prompt, func(match string) string {
+		name := match[1 : len(match)-1]
+		if name == "text" {
+			return text
+		}
+		if value, ok := context[Source(name)]; ok {
+			return value
+		}
+		return match
+	})
+	return strings.TrimSpace(prompt)
+}
@@ -0,0 +1,61 @@
+package prompts
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tr := Template{Name: "tr", Prompt: "Translate:\n\n{text}"}
+	if got := tr.Expand("hola", nil); got != "Translate:\n\nhola" {
+		t.Errorf("got %q", got)
+	}
+
+	noText := Template{Name: "sum", Prompt: "Summarize {document}"}
+	context := map[Source]string{SourceDocument: "the report"}
+	if got := noText.Expand("in one line", context); got != "Summarize the report\n\nin one line" {
+		t.Errorf("got %q", got)
+	}
+	if got := noText.Expand("", context); got != "Summarize the report" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSources(t *testing.T) {
+	tpl := Template{Prompt: "{window} {text} {clipboard} {window}"}
+	if got := tpl.Sources(); !slices.Equal(got, []Source{SourceWindow, SourceClipboard}) {
+		t.Errorf("got %v", got)
+	}
+	if got := (Template{Prompt: "{text}"}).Sources(); got != nil {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(defaultTemplates); err != nil {
+		t.Fatalf("defaults should be valid: %v", err)
+	}
+	invalid := []Template{
+		{Name: "tr", Prompt: "a"},
+		{Name: "TR", Prompt: "b"},
+		{Name: "two words", Prompt: "c"},
+		{Name: "empty"},
+		{Name: "typo", Prompt: "{clipbaord}"},
+	}
+	err := Validate(invalid)
+	if err == nil || len(err.(interface{ Unwrap() []error }).Unwrap()) != 4 {
+		t.Fatalf("expected 4 problems, got %v", err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	loadOnce.Do(func() {})
+	templates = defaultTemplates
+	tpl, text, ok := Match("TR  buenos días ")
+	if !ok || tpl.Name != "tr" || text != "buenos días" {
+		t.Errorf("got %+v %q %v", tpl, text, ok)
+	}
+	if _, _, ok := Match("what is tr"); ok {
+		t.Error("only the first word names a template")
+	}
+}
@@ -0,0 +1,310 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/settings"
+)
+
+var (
+	enginesMu sync.RWMutex
+	// loadOnce reads the engines from the settings on first use
+	loadOnce sync.Once
+	// bareKeywords lets "gh winfastnav" search without the bang in every mode
+	bareKeywords = true
+)
+
+// bang mirrors the fields we use from DuckDuckGo's bang.json.
+type bang struct {
+	Trigger string `json:"t"`
+	Name    string `json:"s"`
+	URL     string `json:"u"`
+}
+
+// Engines returns a copy of the configured search engines.
+func Engines() []g.SearchEngine {
+	loadOnce.Do(loadEngines)
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return append([]g.SearchEngine(nil), g.SearchEngines...)
+}
+
+// Default returns the engine used by internet search mode.
+func Default() g.SearchEngine {
+	loadOnce.Do(loadEngines)
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return defaultEngine(g.SearchEngines)
+}
+
+func defaultEngine(engines []g.SearchEngine) g.SearchEngine {
+	for _, engine := range engines {
+		if engine.Default {
+			return engine
+		}
+	}
+	if len(engines) > 0 {
+		return engines[0]
+	}
+	return g.SearchEngine{Name: "DuckDuckGo", Keyword: "ddg", URL: "https://duckduckgo.com/?q=%s", Default: true}
+}
+
+// Match checks if the query starts with an engine keyword as a bang ("!gh winfastnav"), or
+// bare ("gh winfastnav") in internet search and whenever bare keywords are on. Returns the
+// engine and the remaining search terms.
+func Match(query string, internet bool) (g.SearchEngine, string, bool) {
+	loadOnce.Do(loadEngines)
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return matchEngine(g.SearchEngines, query, internet || bareKeywords)
+}
+
+// BareKeywords reports whether keywords without a bang search outside internet search.
+func BareKeywords() bool {
+	loadOnce.Do(loadEngines)
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return bareKeywords
+}
+
+// SetBareKeywords turns bare keywords outside internet search on or off and saves the choice.
+func SetBareKeywords(on bool) error {
+	loadOnce.Do(loadEngines)
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if err := settings.SetSetting("barekeywords", strconv.FormatBool(on)); err != nil {
+		return err
+	}
+	bareKeywords = on
+	return nil
+}
+
+func matchEngine(engines []g.SearchEngine, query string, bare bool) (g.SearchEngine, string, bool) {
+	keyword, terms, found := strings.Cut(strings.TrimSpace(query), " ")
+	terms = strings.TrimSpace(terms)
+	if !found || terms == "" {
+		return g.SearchEngine{}, "", false
+	}
+	keyword, bang := strings.CutPrefix(keyword, "!")
+	if !bang && !bare {
+		return g.SearchEngine{}, "", false
+	}
+
+	for _, engine := range engines {
+		if engine.Keyword != "" && strings.EqualFold(engine.Keyword, keyword) {
+			return engine, terms, true
+		}
+	}
+	return g.SearchEngine{}, "", false
+}
+
+// URL builds the address to open for the given search terms.
+func URL(engine g.SearchEngine, terms string) string {
+	return strings.ReplaceAll(engine.URL, "%s", url.QueryEscape(terms))
+}
+
+// AddEngine adds a new engine, or replaces the one with the same keyword.
+func AddEngine(engine g.SearchEngine) error {
+	engine.Name = strings.TrimSpace(engine.Name)
+	engine.Keyword = strings.TrimPrefix(strings.TrimSpace(engine.Keyword), "!")
+	engine.URL = strings.TrimSpace(engine.URL)
+
+	if engine.Name == "" || engine.URL == "" {
+		return errors.New("an engine needs a name and a URL")
+	}
+	if strings.Contains(engine.Keyword, " ") {
+		return errors.New("keywords can't contain spaces")
+	}
+	if !strings.Contains(engine.URL, "%s") {
+		return errors.New("the URL must contain %s")
+	}
+
+	loadOnce.Do(loadEngines)
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	engines := append([]g.SearchEngine(nil), g.SearchEngines...)
+	replaced := false
+	for i, existing := range engines {
+		if engine.Keyword != "" && strings.EqualFold(existing.Keyword, engine.Keyword) {
+			engine.Default = existing.Default
+			engines[i] = engine
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		engine.Default = len(engines) == 0
+		engines = append(engines, engine)
+	}
+	return saveEngines(engines)
+}
+
+// RemoveEngine removes the engine at index. If it was the default, the first remaining engine takes over.
+func RemoveEngine(index int) error {
+	loadOnce.Do(loadEngines)
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if index < 0 || index >= len(g.SearchEngines) {
+		return fmt.Errorf("no engine at position %d", index)
+	}
+
+	removed := g.SearchEngines[index]
+	engines := append(append([]g.SearchEngine(nil), g.SearchEngines[:index]...), g.SearchEngines[index+1:]...)
+	if removed.Default && len(engines) > 0 {
+		engines[0].Default = true
+	}
+	return saveEngines(engines)
+}
+
+// SetDefault marks the engine at index as the one used by internet search mode.
+func SetDefault(index int) error {
+	loadOnce.Do(loadEngines)
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if index < 0 || index >= len(g.SearchEngines) {
+		return fmt.Errorf("no engine at position %d", index)
+	}
+
+	engines := append([]g.SearchEngine(nil), g.SearchEngines...)
+	for i := range engines {
+		engines[i].Default = i == index
+	}
+	return saveEngines(engines)
+}
+
+// ImportBangs adds the bangs of a DuckDuckGo-style bang.json file as engines.
+// Keywords that are already configured are left untouched. Returns how many engines were added.
+func ImportBangs(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	loadOnce.Do(loadEngines)
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	engines, added, err := mergeBangs(g.SearchEngines, data)
+	if err != nil {
+		return 0, err
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, saveEngines(engines)
+}
+
+func mergeBangs(engines []g.SearchEngine, data []byte) ([]g.SearchEngine, int, error) {
+	var bangs []bang
+	if err := json.Unmarshal(data, &bangs); err != nil {
+		return nil, 0, fmt.Errorf("invalid bang file: %w", err)
+	}
+
+	known := make(map[string]struct{}, len(engines))
+	for _, engine := range engines {
+		known[strings.ToLower(engine.Keyword)] = struct{}{}
+	}
+
+	merged := append([]g.SearchEngine(nil), engines...)
+	added := 0
+	for _, b := range bangs {
+		keyword := strings.ToLower(strings.TrimSpace(b.Trigger))
+		if keyword == "" || b.URL == "" || !strings.Contains(b.URL, "{{{s}}}") {
+			continue
+		}
+		if _, exists := known[keyword]; exists {
+			continue
+		}
+		known[keyword] = struct{}{}
+
+		name := strings.TrimSpace(b.Name)
+		if name == "" {
+			name = keyword
+		}
+		// Some bangs point back to DuckDuckGo itself with a relative URL
+		target := b.URL
+		if strings.HasPrefix(target, "/") {
+			target = "https://duckduckgo.com" + target
+		}
+		merged = append(merged, g.SearchEngine{
+			Name:    name,
+			Keyword: keyword,
+			URL:     strings.ReplaceAll(target, "{{{s}}}", "%s"),
+		})
+		added++
+	}
+	return merged, added, nil
+}
+
+// defaultSearchEngines returns the engines a fresh install starts with. A search string
+// saved by an older version becomes the default engine.
+func defaultSearchEngines() []g.SearchEngine {
+	defaultURL := "https://duckduckgo.com/?q=%s"
+	if legacy, err := settings.GetSetting("searchstring"); err == nil && len(legacy) > 0 {
+		defaultURL = legacy
+	}
+
+	return []g.SearchEngine{
+		{Name: "DuckDuckGo", Keyword: "ddg", URL: defaultURL, Default: true},
+		{Name: "GitHub", Keyword: "gh", URL: "https://github.com/search?q=%s"},
+		{Name: "StackOverflow", Keyword: "so", URL: "https://stackoverflow.com/search?q=%s"},
+		{Name: "Wikipedia", Keyword: "wiki", URL: "https://en.wikipedia.org/w/index.php?search=%s"},
+	}
+}
+
+// loadEngines reads the saved engines, saving the defaults the first time, and whether
+// bare keywords are on, which they are unless turned off.
+func loadEngines() {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	g.SearchEngines = savedEngines()
+	if unparsed, err := settings.GetSetting("barekeywords"); err == nil && unparsed != "" {
+		if on, err := strconv.ParseBool(unparsed); err == nil {
+			bareKeywords = on
+		} else {
+			log.Printf("Error parsing barekeywords: %v", err)
+		}
+	}
+}
+
+func savedEngines() []g.SearchEngine {
+	unparsedEngines, err := settings.GetSetting("searchengines")
+	if err == nil && len(unparsedEngines) > 0 {
+		var engines []g.SearchEngine
+		if err = json.Unmarshal([]byte(unparsedEngines), &engines); err == nil && len(engines) > 0 {
+			return engines
+		}
+		log.Printf("Error parsing search engines: %v", err)
+	}
+
+	engines := defaultSearchEngines()
+	jsonData, err := json.Marshal(engines)
+	if err != nil {
+		log.Printf("Error encoding search engines: %v", err)
+		return engines
+	}
+	if err = settings.SetSetting("searchengines", string(jsonData)); err != nil {
+		log.Printf("Error setting search engines: %v", err)
+	}
+	return engines
+}
+
+// saveEngines persists the list and makes it the active one. enginesMu must be held.
+func saveEngines(engines []g.SearchEngine) error {
+	jsonData, err := json.Marshal(engines)
+	if err != nil {
+		return err
+	}
+	if err = settings.SetSetting("searchengines", string(jsonData)); err != nil {
+		return err
+	}
+	g.SearchEngines = engines
+	return nil
+}
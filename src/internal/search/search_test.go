@@ -0,0 +1,69 @@
+package search
+
+import (
+	"testing"
+	g "winfastnav/internal/globals"
+)
+
+var testEngines = []g.SearchEngine{
+	{Name: "DuckDuckGo", Keyword: "ddg", URL: "https://duckduckgo.com/?q=%s", Default: true},
+	{Name: "GitHub", Keyword: "gh", URL: "https://github.com/search?q=%s"},
+}
+
+func TestMatchEngineByKeywordAndBang(t *testing.T) {
+	for _, query := range []string{"gh winfastnav go", "!gh winfastnav go", "GH winfastnav go"} {
+		engine, terms, ok := matchEngine(testEngines, query, true)
+		if !ok || engine.Keyword != "gh" || terms != "winfastnav go" {
+			t.Fatalf("%q: unexpected match %+v %q %v", query, engine, terms, ok)
+		}
+	}
+
+	for _, query := range []string{"gh", "gh ", "github winfastnav", "notepad"} {
+		if _, _, ok := matchEngine(testEngines, query, true); ok {
+			t.Fatalf("%q should not match an engine", query)
+		}
+	}
+}
+
+func TestBareKeywordsOnlyMatchWhenAllowed(t *testing.T) {
+	// "gh desktop" is an app, not a GitHub search, outside internet search
+	if _, _, ok := matchEngine(testEngines, "gh desktop", false); ok {
+		t.Fatal("a bare keyword matched")
+	}
+	if engine, terms, ok := matchEngine(testEngines, "!gh desktop", false); !ok || engine.Keyword != "gh" || terms != "desktop" {
+		t.Fatalf("unexpected bang match %+v %q %v", engine, terms, ok)
+	}
+}
+
+func TestURLEscapesTerms(t *testing.T) {
+	got := URL(testEngines[1], "a&b c")
+	if got != "https://github.com/search?q=a%26b+c" {
+		t.Fatalf("unexpected url %q", got)
+	}
+}
+
+func TestMergeBangsSkipsKnownKeywords(t *testing.T) {
+	data := []byte(`[
+		{"t": "gh", "s": "GitHub (bang)", "u": "https://github.com/search?q={{{s}}}"},
+		{"t": "so", "s": "StackOverflow", "u": "https://stackoverflow.com/search?q={{{s}}}"},
+		{"t": "ddgi", "s": "DuckDuckGo Images", "u": "/?q={{{s}}}&ia=images"},
+		{"t": "nourl", "s": "Broken", "u": "https://example.com"}
+	]`)
+
+	engines, added, err := mergeBangs(testEngines, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 || len(engines) != 4 {
+		t.Fatalf("unexpected merge result: added %d, %+v", added, engines)
+	}
+	if engines[1].Name != "GitHub" {
+		t.Fatalf("existing engine was overwritten: %+v", engines[1])
+	}
+	if engines[3].URL != "https://duckduckgo.com/?q=%s&ia=images" {
+		t.Fatalf("relative bang url not resolved: %q", engines[3].URL)
+	}
+	if defaultEngine(engines).Keyword != "ddg" {
+		t.Fatalf("default engine changed after import")
+	}
+}
@@ -2,16 +2,19 @@ package settings
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 	g "winfastnav/internal/globals"
 )
 
 type Settings map[string]string
 
+// mu keeps SetSetting's read, change and write from interleaving with other reads and writes.
+var mu sync.Mutex
+
 func SetupSettings() {
 	unparsedList, err := GetSetting("blocklist")
 	if err != nil || len(unparsedList) == 0 {
@@ -24,43 +27,60 @@ func SetupSettings() {
 		unparsedList = "[]"
 	}
 
-	var blocklist []string
-	err = json.Unmarshal([]byte(unparsedList), &blocklist)
+	blocklist, err := parseBlocklist(unparsedList)
 	if err != nil {
 		log.Printf("Error parsing blocklist: %v", err)
-		blocklist = []string{}
+		blocklist = []g.BlockRule{}
 		if err = SetSetting("blocklist", "[]"); err != nil {
 			log.Printf("Error resetting blocklist: %v", err)
 		}
 	}
 
 	g.ExecBlocklist = blocklist
+}
 
-	g.SearchString, err = GetSetting("searchstring")
-	if err != nil || len(g.SearchString) == 0 {
-		// initialize with empty list string
-		err = SetSetting("searchstring", "https://duckduckgo.com/?q=%s")
-		if err != nil {
-			log.Printf("Error setting searchstring: %v", err)
-			return
-		}
-		g.SearchString = "https://duckduckgo.com/?q=%s"
+// parseBlocklist reads the saved rules. Older versions saved a plain list of paths,
+// those become exact path rules.
+func parseBlocklist(unparsed string) ([]g.BlockRule, error) {
+	var rules []g.BlockRule
+	if err := json.Unmarshal([]byte(unparsed), &rules); err == nil {
+		return rules, nil
+	}
+
+	var paths []string
+	if err := json.Unmarshal([]byte(unparsed), &paths); err != nil {
+		return nil, err
 	}
+	rules = make([]g.BlockRule, 0, len(paths))
+	for _, path := range paths {
+		rules = append(rules, g.BlockRule{Kind: g.BlockPath, Pattern: path})
+	}
+	return rules, nil
 }
 
-func getSettingsFilePath() (string, error) {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
-		return "", errors.New("can't find appdata")
+// Dir returns the folder winfastnav keeps its files in, %APPDATA%\winfastnav on Windows
+// and ~/.config/winfastnav on Linux, creating it if needed.
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("can't find the config directory: %w", err)
 	}
 
-	dir := filepath.Join(appData, "winfastnav")
+	dir := filepath.Join(configDir, "winfastnav")
 
-	err := os.MkdirAll(dir, 0o700)
+	err = os.MkdirAll(dir, 0o700)
 	if err != nil {
 		return "", fmt.Errorf("failed to create app directory: %w", err)
 	}
 
+	return dir, nil
+}
+
+func getSettingsFilePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
 	return filepath.Join(dir, "prefs.json"), nil
 }
 
@@ -105,7 +125,9 @@ func writeSettings(s Settings) error {
 		return err
 	}
 
-	file, err := os.Create(path)
+	// written next to the settings and renamed over them, so a reader never sees half a file
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
 	if err != nil {
 		return err
 	}
@@ -119,11 +141,13 @@ func writeSettings(s Settings) error {
 	if err = file.Close(); err != nil {
 		return err
 	}
-	return nil
+	return os.Rename(tmp, path)
 }
 
 // SetSetting stores a key-value pair in the settings and persists it to file.
 func SetSetting(key, value string) error {
+	mu.Lock()
+	defer mu.Unlock()
 	settings, err := readSettings()
 	if err != nil {
 		return err
@@ -137,6 +161,8 @@ func SetSetting(key, value string) error {
 // GetSetting retrieves the value for a given key from settings.
 // Returns (value, true) if found, or ("", false) if the key does not exist.
 func GetSetting(key string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
 	settings, err := readSettings()
 
 	if err != nil {
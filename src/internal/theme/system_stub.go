@@ -0,0 +1,21 @@
+//go:build !windows
+
+package theme
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SystemPrefersDark asks GNOME-compatible desktops for their color scheme, or checks GTK_THEME.
+func SystemPrefersDark() bool {
+	if gtkTheme := os.Getenv("GTK_THEME"); gtkTheme != "" {
+		return strings.HasSuffix(strings.ToLower(gtkTheme), ":dark")
+	}
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+	if err != nil {
+		return true
+	}
+	return strings.Contains(string(out), "dark")
+}
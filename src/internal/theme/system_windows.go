@@ -0,0 +1,22 @@
+//go:build windows
+
+package theme
+
+import "golang.org/x/sys/windows/registry"
+
+// SystemPrefersDark reads the "Choose your app mode" setting.
+func SystemPrefersDark() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER,
+		`Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`,
+		registry.QUERY_VALUE)
+	if err != nil {
+		return true
+	}
+	defer func() { _ = key.Close() }()
+
+	light, _, err := key.GetIntegerValue("AppsUseLightTheme")
+	if err != nil {
+		return true
+	}
+	return light == 0
+}
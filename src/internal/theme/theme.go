@@ -0,0 +1,286 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"winfastnav/internal/settings"
+)
+
+// System is the theme name that follows the OS light/dark preference.
+const System = "system"
+
+// Color is an NRGBA color stored as "#rrggbb" or "#rrggbbaa" in the settings file.
+type Color color.NRGBA
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	if c.A == 0xff {
+		return json.Marshal(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+	return json.Marshal(fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A))
+}
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseColor(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// ParseColor reads "#rgb", "#rrggbb" or "#rrggbbaa".
+func ParseColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	return Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// NRGBA converts the color for drawing.
+func (c Color) NRGBA() color.NRGBA { return color.NRGBA(c) }
+
+type Palette struct {
+	Background Color `json:"background"`
+	Input      Color `json:"input"`
+	Button     Color `json:"button"`
+	ButtonText Color `json:"buttonText"`
+	Text       Color `json:"text"`
+	Hint       Color `json:"hint"`
+	Section    Color `json:"section"`
+	Separator  Color `json:"separator"`
+	// Match colors the characters of a result that matched the query.
+	Match Color `json:"match"`
+}
+
+// SelectedRow is how the highlighted result is drawn. Prefix goes before its title, the
+// built-in themes mark it with the background alone.
+type SelectedRow struct {
+	Background Color  `json:"background"`
+	Text       Color  `json:"text"`
+	Prefix     string `json:"prefix"`
+}
+
+// Theme describes how the launcher looks. Sizes are in Gio sp (text) and dp (everything else).
+type Theme struct {
+	Name         string      `json:"name"`
+	Extends      string      `json:"extends,omitempty"`
+	Palette      Palette     `json:"palette"`
+	FontFamily   string      `json:"fontFamily,omitempty"`
+	TextSize     float32     `json:"textSize"`
+	WindowInset  float32     `json:"windowInset"`
+	ButtonInset  float32     `json:"buttonInset"`
+	InputInset   float32     `json:"inputInset"`
+	CornerRadius float32     `json:"cornerRadius"`
+	Selected     SelectedRow `json:"selected"`
+}
+
+var builtins = []Theme{
+	{
+		Name: "dark",
+		Palette: Palette{
+			Background: Color{R: 0x1a, G: 0x18, B: 0x18, A: 0xff},
+			Input:      Color{R: 0x2b, G: 0x2b, B: 0x2b, A: 0xff},
+			Button:     Color{R: 0x46, G: 0x38, B: 0x38, A: 0xff},
+			ButtonText: Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			Text:       Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			Hint:       Color{R: 0xb4, G: 0xb4, B: 0xb4, A: 0xff},
+			Section:    Color{R: 0xc8, G: 0xc8, B: 0xc8, A: 0xff},
+			Separator:  Color{R: 0x4a, G: 0x4a, B: 0x4a, A: 0xff},
+			Match:      Color{R: 0xff, G: 0xb8, B: 0x6c, A: 0xff},
+		},
+		TextSize:    12.35,
+		WindowInset: 10,
+		ButtonInset: 6,
+		InputInset:  5,
+		Selected: SelectedRow{
+			Background: Color{R: 0x46, G: 0x38, B: 0x38, A: 0xff},
+			Text:       Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		},
+	},
+	{
+		Name: "light",
+		Palette: Palette{
+			Background: Color{R: 0xf3, G: 0xf1, B: 0xf1, A: 0xff},
+			Input:      Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			Button:     Color{R: 0xe0, G: 0xd6, B: 0xd6, A: 0xff},
+			ButtonText: Color{R: 0x1a, G: 0x18, B: 0x18, A: 0xff},
+			Text:       Color{R: 0x1a, G: 0x18, B: 0x18, A: 0xff},
+			Hint:       Color{R: 0x70, G: 0x70, B: 0x70, A: 0xff},
+			Section:    Color{R: 0x50, G: 0x50, B: 0x50, A: 0xff},
+			Separator:  Color{R: 0xc8, G: 0xc8, B: 0xc8, A: 0xff},
+			Match:      Color{R: 0xb3, G: 0x47, B: 0x00, A: 0xff},
+		},
+		TextSize:     12.35,
+		WindowInset:  10,
+		ButtonInset:  6,
+		InputInset:   5,
+		CornerRadius: 3,
+		Selected: SelectedRow{
+			Background: Color{R: 0xb8, G: 0x9c, B: 0x9c, A: 0xff},
+			Text:       Color{R: 0x1a, G: 0x18, B: 0x18, A: 0xff},
+		},
+	},
+	{
+		Name: "high-contrast",
+		Palette: Palette{
+			Background: Color{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+			Input:      Color{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+			Button:     Color{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+			ButtonText: Color{R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+			Text:       Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			Hint:       Color{R: 0x00, G: 0xff, B: 0xff, A: 0xff},
+			Section:    Color{R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+			Separator:  Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			Match:      Color{R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+		},
+		TextSize:    14,
+		WindowInset: 10,
+		ButtonInset: 7,
+		InputInset:  6,
+		Selected: SelectedRow{
+			Background: Color{R: 0x00, G: 0xff, B: 0xff, A: 0xff},
+			Text:       Color{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+		},
+	},
+}
+
+// Builtin returns the built-in theme with the given name.
+func Builtin(name string) (Theme, bool) {
+	for _, t := range builtins {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Names lists the selectable themes: "system", the built-ins and any custom themes.
+func Names() []string {
+	names := []string{System}
+	for _, t := range builtins {
+		names = append(names, t.Name)
+	}
+	for _, raw := range customThemes() {
+		var header struct{ Name string }
+		if json.Unmarshal(raw, &header) != nil || header.Name == "" || slices.Contains(names, header.Name) {
+			continue
+		}
+		names = append(names, header.Name)
+	}
+	return names
+}
+
+var (
+	selectedMu sync.Mutex
+	// selected caches the "theme" setting once read, empty until then
+	selected string
+)
+
+// Selected returns the name saved in the "theme" setting, "system" by default.
+func Selected() string {
+	selectedMu.Lock()
+	defer selectedMu.Unlock()
+	if selected == "" {
+		selected = System
+		if name, err := settings.GetSetting("theme"); err == nil && name != "" {
+			selected = name
+		}
+	}
+	return selected
+}
+
+// Select saves the theme name to use.
+func Select(name string) error {
+	if err := settings.SetSetting("theme", name); err != nil {
+		return err
+	}
+	selectedMu.Lock()
+	selected = name
+	selectedMu.Unlock()
+	return nil
+}
+
+// Current resolves the selected theme. "system" picks light or dark from the OS preference,
+// unknown names fall back to dark.
+func Current() Theme {
+	return Resolve(Selected(), customThemes(), SystemPrefersDark)
+}
+
+// Resolve finds the named theme among the custom and built-in ones.
+func Resolve(name string, custom []json.RawMessage, prefersDark func() bool) Theme {
+	if name == System {
+		name = "light"
+		if prefersDark() {
+			name = "dark"
+		}
+	}
+
+	for _, raw := range custom {
+		var header struct{ Name, Extends string }
+		if err := json.Unmarshal(raw, &header); err != nil || header.Name != name {
+			continue
+		}
+		t, err := fromJSON(raw, header.Extends)
+		if err != nil {
+			log.Printf("Error parsing theme %q: %v", name, err)
+			break
+		}
+		return t
+	}
+
+	if t, ok := Builtin(name); ok {
+		return t
+	}
+	t, _ := Builtin("dark")
+	return t
+}
+
+// fromJSON reads a custom theme on top of the built-in it extends, so it only needs the fields it changes.
+func fromJSON(raw json.RawMessage, extends string) (Theme, error) {
+	t, ok := Builtin(extends)
+	if !ok {
+		t, _ = Builtin("dark")
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return Theme{}, err
+	}
+	if t.TextSize <= 0 {
+		return Theme{}, fmt.Errorf("textSize must be positive")
+	}
+	return t, nil
+}
+
+// customThemes reads the "customthemes" setting, a JSON list of theme definitions.
+func customThemes() []json.RawMessage {
+	unparsed, err := settings.GetSetting("customthemes")
+	if err != nil || unparsed == "" {
+		return nil
+	}
+	var custom []json.RawMessage
+	if err = json.Unmarshal([]byte(unparsed), &custom); err != nil {
+		log.Printf("Error parsing custom themes: %v", err)
+		return nil
+	}
+	return custom
+}
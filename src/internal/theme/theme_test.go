@@ -0,0 +1,68 @@
+package theme
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]Color{
+		"#fff":      {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		"#1a1818":   {R: 0x1a, G: 0x18, B: 0x18, A: 0xff},
+		" 46383880": {R: 0x46, G: 0x38, B: 0x38, A: 0x80},
+	}
+	for input, want := range cases {
+		got, err := ParseColor(input)
+		if err != nil || got != want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "#12", "#12345", "#gggggg"} {
+		if _, err := ParseColor(input); err == nil {
+			t.Errorf("ParseColor(%q) should fail", input)
+		}
+	}
+}
+
+func TestColorJSONRoundTrips(t *testing.T) {
+	for _, c := range []Color{{R: 1, G: 2, B: 3, A: 0xff}, {R: 0xaa, G: 0xbb, B: 0xcc, A: 0x10}} {
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again Color
+		if err = json.Unmarshal(data, &again); err != nil || again != c {
+			t.Errorf("%s did not round trip: %v %v", data, again, err)
+		}
+	}
+}
+
+func TestResolveSystemFollowsPreference(t *testing.T) {
+	if got := Resolve(System, nil, func() bool { return true }); got.Name != "dark" {
+		t.Errorf("dark preference resolved to %q", got.Name)
+	}
+	if got := Resolve(System, nil, func() bool { return false }); got.Name != "light" {
+		t.Errorf("light preference resolved to %q", got.Name)
+	}
+	if got := Resolve("missing", nil, nil); got.Name != "dark" {
+		t.Errorf("unknown theme resolved to %q", got.Name)
+	}
+}
+
+func TestResolveCustomExtendsBuiltin(t *testing.T) {
+	custom := []json.RawMessage{
+		json.RawMessage(`{"name": "broken", "textSize": -1}`),
+		json.RawMessage(`{"name": "solar", "extends": "light", "palette": {"background": "#fdf6e3"}, "textSize": 15}`),
+	}
+	got := Resolve("solar", custom, nil)
+	light, _ := Builtin("light")
+	if got.Name != "solar" || got.TextSize != 15 || got.CornerRadius != light.CornerRadius {
+		t.Fatalf("unexpected theme: %+v", got)
+	}
+	if got.Palette.Background != (Color{R: 0xfd, G: 0xf6, B: 0xe3, A: 0xff}) || got.Palette.Text != light.Palette.Text {
+		t.Fatalf("custom palette should only override background: %+v", got.Palette)
+	}
+	if got := Resolve("broken", custom, nil); got.Name != "dark" {
+		t.Errorf("invalid custom theme resolved to %q", got.Name)
+	}
+}
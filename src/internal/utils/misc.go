@@ -1,21 +1,6 @@
 package utils
 
-import (
-	"fmt"
-	"golang.org/x/sys/windows/registry"
-	"io"
-	"log"
-	"net/http"
-	"os"
-	"os/exec"
-	"strings"
-	"syscall"
-	"time"
-)
-
-const maxHTTPResponseSize = 1 << 20
-
-var httpClient = &http.Client{Timeout: 10 * time.Second}
+import "strings"
 
 func StartsWith(s, prefix string) bool {
 	if len(s) < len(prefix) {
@@ -24,31 +9,6 @@ func StartsWith(s, prefix string) bool {
 	return s[:len(prefix)] == prefix
 }
 
-func HttpGet(url string) (string, error) {
-	resp, err := httpClient.Get(url)
-
-	if err != nil {
-		return "", err
-	}
-
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(resp.Body)
-
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return "", fmt.Errorf("request failed: %s", resp.Status)
-	}
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseSize+1))
-	if err != nil {
-		return "", err
-	}
-	if len(body) > maxHTTPResponseSize {
-		return "", fmt.Errorf("response exceeds %d bytes", maxHTTPResponseSize)
-	}
-	return string(body), nil
-}
-
 func WrapTextByWords(s string, maxLen int) string {
 	if maxLen <= 0 {
 		return s
@@ -95,30 +55,3 @@ func ContainsAny(s string, subs []string) bool {
 	}
 	return false
 }
-
-func AddToStartup() error {
-	exePath, err := os.Executable()
-	if err != nil {
-		return err
-	}
-
-	key, err := registry.OpenKey(registry.CURRENT_USER,
-		`Software\Microsoft\Windows\CurrentVersion\Run`,
-		registry.SET_VALUE)
-	if err != nil {
-		return err
-	}
-
-	err = key.SetStringValue("WinFastNav", exePath)
-	_ = key.Close()
-	return err
-}
-
-func OpenURI(uri string) error {
-	log.Printf("Opening URI: %s", uri)
-	cmd := exec.Command("rundll32", "url.dll,FileProtocolHandler", uri)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow: true,
-	}
-	return cmd.Start()
-}
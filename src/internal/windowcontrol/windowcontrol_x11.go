@@ -0,0 +1,74 @@
+//go:build !windows
+
+package windowcontrol
+
+import (
+	"fmt"
+	"sync"
+
+	"gioui.org/app"
+	"winfastnav/internal/windowmanager"
+)
+
+// Controller hides and raises the launcher through the X11 window manager.
+// Without one (plain Wayland) every call is a no-op and Gio keeps managing the window.
+type Controller struct {
+	mu      sync.Mutex
+	title   string
+	id      uint64
+	manager windowmanager.WindowManager
+}
+
+func New(title string) *Controller {
+	c := &Controller{title: title}
+	c.manager, _ = windowmanager.New()
+	return c
+}
+
+// BindView takes the window ID from Gio's X11 view event, and forgets it when the view goes away.
+// Wayland views have no ID the window manager knows, so Bind keeps finding the window by title.
+func (c *Controller) BindView(view any) {
+	e, ok := view.(app.X11ViewEvent)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	c.id = uint64(e.Window)
+	c.mu.Unlock()
+}
+
+// Bind finds the launcher window if no view event named it, and returns its ID.
+func (c *Controller) Bind() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.manager == nil || c.id != 0 {
+		return c.id, nil
+	}
+	windows, err := c.manager.List()
+	if err != nil {
+		return 0, err
+	}
+	for _, window := range windows {
+		if window.Title == c.title {
+			c.id = window.ID
+			return c.id, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find Gio window %q", c.title)
+}
+
+func (c *Controller) Hide() error {
+	id, err := c.Bind()
+	if err != nil || c.manager == nil {
+		return err
+	}
+	return c.manager.Minimize(id)
+}
+
+func (c *Controller) ShowAndFocus() error {
+	id, err := c.Bind()
+	if err != nil || c.manager == nil {
+		return err
+	}
+	return c.manager.Focus(id)
+}
@@ -0,0 +1,110 @@
+package windowmanager
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"image"
+	"slices"
+)
+
+// Action is something the switcher can do to a window.
+type Action string
+
+const (
+	ActionFocus       Action = "focus"
+	ActionClose       Action = "close"
+	ActionMinimize    Action = "minimize"
+	ActionMaximize    Action = "maximize"
+	ActionAlwaysOnTop Action = "always on top"
+	ActionNextMonitor Action = "next monitor"
+	ActionSnapLeft    Action = "snap left"
+	ActionSnapRight   Action = "snap right"
+	ActionKill        Action = "kill"
+)
+
+// Perform applies action to the window. Maximize restores a maximized window, and always on top
+// is switched off again when it is on.
+func Perform(wm WindowManager, id uint64, action Action) error {
+	switch action {
+	case ActionFocus:
+		return wm.Focus(id)
+	case ActionClose:
+		return wm.Close(id)
+	case ActionMinimize:
+		return wm.Minimize(id)
+	case ActionMaximize:
+		return wm.ToggleMaximize(id)
+	case ActionAlwaysOnTop:
+		return wm.ToggleAlwaysOnTop(id)
+	case ActionKill:
+		return wm.KillProcess(id)
+	case ActionNextMonitor, ActionSnapLeft, ActionSnapRight:
+		bounds, err := wm.Bounds(id)
+		if err != nil {
+			return err
+		}
+		areas, err := Monitors(wm)
+		if err != nil {
+			return err
+		}
+		current := MonitorOf(bounds, areas)
+		var target image.Rectangle
+		switch action {
+		case ActionSnapLeft:
+			area := areas[current]
+			target = image.Rect(area.Min.X, area.Min.Y, area.Min.X+area.Dx()/2, area.Max.Y)
+		case ActionSnapRight:
+			area := areas[current]
+			target = image.Rect(area.Min.X+area.Dx()/2, area.Min.Y, area.Max.X, area.Max.Y)
+		default:
+			if len(areas) == 1 {
+				return errors.New("there is only one monitor")
+			}
+			target = moveTo(bounds, areas[current], areas[(current+1)%len(areas)])
+		}
+		return wm.Move(id, target)
+	}
+	return fmt.Errorf("unknown window action %q", action)
+}
+
+// Monitors returns the work areas from left to right. The next monitor is the one to the right,
+// wrapping around to the leftmost.
+func Monitors(wm WindowManager) ([]image.Rectangle, error) {
+	areas, err := wm.WorkAreas()
+	if err != nil {
+		return nil, err
+	}
+	if len(areas) == 0 {
+		return nil, errors.New("no monitors found")
+	}
+	slices.SortFunc(areas, func(a, b image.Rectangle) int {
+		return cmp.Or(cmp.Compare(a.Min.X, b.Min.X), cmp.Compare(a.Min.Y, b.Min.Y))
+	})
+	return areas, nil
+}
+
+// MonitorOf returns the index of the area holding most of bounds, the first one when it is off screen.
+func MonitorOf(bounds image.Rectangle, areas []image.Rectangle) int {
+	best, bestOverlap := 0, 0
+	for i, area := range areas {
+		overlap := bounds.Intersect(area)
+		if size := overlap.Dx() * overlap.Dy(); size > bestOverlap {
+			best, bestOverlap = i, size
+		}
+	}
+	return best
+}
+
+// moveTo places bounds at the same relative position in to as it has in from, shrinking it
+// to fit when the other monitor is smaller.
+func moveTo(bounds, from, to image.Rectangle) image.Rectangle {
+	width, height := min(bounds.Dx(), to.Dx()), min(bounds.Dy(), to.Dy())
+	offset := bounds.Min.Sub(from.Min)
+	if from.Dx() > 0 && from.Dy() > 0 {
+		offset = image.Pt(offset.X*to.Dx()/from.Dx(), offset.Y*to.Dy()/from.Dy())
+	}
+	x := min(max(to.Min.X+offset.X, to.Min.X), to.Max.X-width)
+	y := min(max(to.Min.Y+offset.Y, to.Min.Y), to.Max.Y-height)
+	return image.Rect(x, y, x+width, y+height)
+}
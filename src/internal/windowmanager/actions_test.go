@@ -0,0 +1,102 @@
+package windowmanager
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+// fakeManager records the calls made to it, with one window at bounds.
+type fakeManager struct {
+	bounds image.Rectangle
+	areas  []image.Rectangle
+	calls  []string
+	moved  image.Rectangle
+}
+
+func (m *fakeManager) List() ([]Window, error)  { return []Window{{ID: 1, Title: "Editor"}}, nil }
+func (m *fakeManager) Active() (Window, error)  { return Window{ID: 1, Title: "Editor"}, nil }
+func (m *fakeManager) Focus(id uint64) error    { m.calls = append(m.calls, "focus"); return nil }
+func (m *fakeManager) Minimize(id uint64) error { m.calls = append(m.calls, "minimize"); return nil }
+func (m *fakeManager) Close(id uint64) error    { m.calls = append(m.calls, "close"); return nil }
+func (m *fakeManager) KillProcess(uint64) error { m.calls = append(m.calls, "kill"); return nil }
+func (m *fakeManager) Icon(uint64) image.Image  { return nil }
+func (m *fakeManager) ToggleMaximize(uint64) error {
+	m.calls = append(m.calls, "maximize")
+	return nil
+}
+func (m *fakeManager) ToggleAlwaysOnTop(uint64) error {
+	m.calls = append(m.calls, "always on top")
+	return nil
+}
+func (m *fakeManager) Bounds(uint64) (image.Rectangle, error) { return m.bounds, nil }
+func (m *fakeManager) WorkAreas() ([]image.Rectangle, error)  { return m.areas, nil }
+func (m *fakeManager) Move(id uint64, bounds image.Rectangle) error {
+	m.calls = append(m.calls, "move")
+	m.moved = bounds
+	return nil
+}
+
+func TestPerformSimpleActions(t *testing.T) {
+	m := &fakeManager{}
+	for _, action := range []Action{ActionFocus, ActionClose, ActionMinimize, ActionMaximize, ActionAlwaysOnTop, ActionKill} {
+		if err := Perform(m, 1, action); err != nil {
+			t.Fatalf("%s: %v", action, err)
+		}
+	}
+	if want := []string{"focus", "close", "minimize", "maximize", "always on top", "kill"}; !slices.Equal(m.calls, want) {
+		t.Errorf("calls = %v, want %v", m.calls, want)
+	}
+	if err := Perform(m, 1, "fly"); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
+
+func TestSnapUsesTheMonitorHoldingTheWindow(t *testing.T) {
+	m := &fakeManager{
+		// mostly on the right monitor, which has a taskbar along the bottom
+		bounds: image.Rect(1800, 100, 2600, 700),
+		areas:  []image.Rectangle{image.Rect(0, 0, 1920, 1080), image.Rect(1920, 0, 3840, 1040)},
+	}
+	if err := Perform(m, 1, ActionSnapLeft); err != nil {
+		t.Fatal(err)
+	}
+	if want := image.Rect(1920, 0, 2880, 1040); m.moved != want {
+		t.Errorf("snapped left to %v, want %v", m.moved, want)
+	}
+	if err := Perform(m, 1, ActionSnapRight); err != nil {
+		t.Fatal(err)
+	}
+	if want := image.Rect(2880, 0, 3840, 1040); m.moved != want {
+		t.Errorf("snapped right to %v, want %v", m.moved, want)
+	}
+}
+
+func TestNextMonitorKeepsTheRelativePosition(t *testing.T) {
+	m := &fakeManager{
+		bounds: image.Rect(100, 100, 900, 700),
+		// listed out of order, the next monitor is the one to the right
+		areas: []image.Rectangle{image.Rect(1920, 0, 3200, 720), image.Rect(0, 0, 1920, 1080)},
+	}
+	if err := Perform(m, 1, ActionNextMonitor); err != nil {
+		t.Fatal(err)
+	}
+	if want := image.Rect(1920+66, 66, 1920+66+800, 66+600); m.moved != want {
+		t.Errorf("moved to %v, want %v", m.moved, want)
+	}
+
+	// from the rightmost monitor back to the first, shrunk to fit a smaller screen
+	m.bounds = image.Rect(1920, 0, 3200, 720)
+	m.areas = []image.Rectangle{image.Rect(0, 0, 1024, 600), image.Rect(1920, 0, 3200, 720)}
+	if err := Perform(m, 1, ActionNextMonitor); err != nil {
+		t.Fatal(err)
+	}
+	if want := image.Rect(0, 0, 1024, 600); m.moved != want {
+		t.Errorf("moved to %v, want %v", m.moved, want)
+	}
+
+	m.areas = m.areas[:1]
+	if err := Perform(m, 1, ActionNextMonitor); err == nil {
+		t.Error("expected an error with a single monitor")
+	}
+}
@@ -0,0 +1,332 @@
+package windowmanager
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// xConn is the part of an X11 connection the EWMH window manager needs, so it can be faked in tests.
+type xConn interface {
+	Root() uint32
+	Atom(name string) (uint32, error)
+	// Property returns the raw value of the named property, empty when it isn't set.
+	Property(window uint32, name string) ([]byte, error)
+	// ClientMessage sends a 32-bit client message about window to the root window, which is how EWMH requests are made.
+	ClientMessage(window uint32, messageType string, data ...uint32) error
+	// Geometry returns the area of window, without the window manager's frame, in root coordinates.
+	Geometry(window uint32) (image.Rectangle, error)
+	// Monitors lists the area of each monitor in root coordinates.
+	Monitors() ([]image.Rectangle, error)
+}
+
+// ewmh talks to any window manager that follows the Extended Window Manager Hints spec.
+type ewmh struct {
+	conn xConn
+}
+
+// window types that aren't shown in a taskbar, and so aren't offered in the switcher
+var hiddenTypes = []string{
+	"_NET_WM_WINDOW_TYPE_DESKTOP",
+	"_NET_WM_WINDOW_TYPE_DOCK",
+	"_NET_WM_WINDOW_TYPE_TOOLBAR",
+	"_NET_WM_WINDOW_TYPE_UTILITY",
+	"_NET_WM_WINDOW_TYPE_MENU",
+	"_NET_WM_WINDOW_TYPE_SPLASH",
+	"_NET_WM_WINDOW_TYPE_NOTIFICATION",
+}
+
+// List goes through _NET_CLIENT_LIST_STACKING from the top, so the window used last comes
+// first. Window managers without it only give the mapping order of _NET_CLIENT_LIST.
+func (e ewmh) List() ([]Window, error) {
+	var ids []uint32
+	stacking, err := e.conn.Property(e.conn.Root(), "_NET_CLIENT_LIST_STACKING")
+	if err != nil {
+		return nil, err
+	}
+	if ids = uint32s(stacking); len(ids) > 0 {
+		// bottom to top
+		slices.Reverse(ids)
+	} else {
+		clients, err := e.conn.Property(e.conn.Root(), "_NET_CLIENT_LIST")
+		if err != nil {
+			return nil, err
+		}
+		ids = uint32s(clients)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: the window manager doesn't publish _NET_CLIENT_LIST", ErrUnsupported)
+	}
+
+	var windows []Window
+	for _, id := range ids {
+		if e.hidden(id) {
+			continue
+		}
+		if title := e.title(id); title != "" {
+			windows = append(windows, Window{ID: uint64(id), Title: title, Process: e.class(id)})
+		}
+	}
+	return windows, nil
+}
+
+func (e ewmh) hidden(id uint32) bool {
+	if e.hasAtom(id, "_NET_WM_STATE", "_NET_WM_STATE_SKIP_TASKBAR") {
+		return true
+	}
+	for _, kind := range hiddenTypes {
+		if e.hasAtom(id, "_NET_WM_WINDOW_TYPE", kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAtom reports whether the atom list in property contains the named atom.
+func (e ewmh) hasAtom(id uint32, property, name string) bool {
+	value, err := e.conn.Property(id, property)
+	if err != nil || len(value) == 0 {
+		return false
+	}
+	atom, err := e.conn.Atom(name)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(uint32s(value), atom)
+}
+
+// title prefers the UTF-8 _NET_WM_NAME and falls back to the legacy WM_NAME.
+func (e ewmh) title(id uint32) string {
+	for _, property := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		if value, err := e.conn.Property(id, property); err == nil && len(value) > 0 {
+			return string(value)
+		}
+	}
+	return ""
+}
+
+// class returns the instance part of WM_CLASS, which is usually the executable name.
+func (e ewmh) class(id uint32) string {
+	value, err := e.conn.Property(id, "WM_CLASS")
+	if err != nil {
+		return ""
+	}
+	// two NUL-terminated strings, the instance name and the class name
+	instance, class, _ := strings.Cut(string(value), "\x00")
+	if instance == "" {
+		instance, _, _ = strings.Cut(class, "\x00")
+	}
+	return instance
+}
+
+// preferred size of the icons, the closest one _NET_WM_ICON offers is used
+const iconSize = 32
+
+// Icon decodes the _NET_WM_ICON image nearest iconSize. The property holds any number of
+// images, each a width and a height followed by that many ARGB pixels.
+func (e ewmh) Icon(id uint64) image.Image {
+	value, err := e.conn.Property(uint32(id), "_NET_WM_ICON")
+	if err != nil {
+		return nil
+	}
+	data := uint32s(value)
+	best, bestWidth, bestHeight := -1, 0, 0
+	for i := 0; i+2 <= len(data); {
+		width, height := int(data[i]), int(data[i+1])
+		if width <= 0 || height <= 0 || width*height > len(data)-i-2 {
+			// cut off by the property size limit, or not an icon at all
+			break
+		}
+		if best < 0 || abs(width-iconSize) < abs(bestWidth-iconSize) {
+			best, bestWidth, bestHeight = i+2, width, height
+		}
+		i += 2 + width*height
+	}
+	if best < 0 {
+		return nil
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, bestWidth, bestHeight))
+	for i, argb := range data[best : best+bestWidth*bestHeight] {
+		img.Pix[i*4] = uint8(argb >> 16)
+		img.Pix[i*4+1] = uint8(argb >> 8)
+		img.Pix[i*4+2] = uint8(argb)
+		img.Pix[i*4+3] = uint8(argb >> 24)
+	}
+	return img
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (e ewmh) Active() (Window, error) {
+	value, err := e.conn.Property(e.conn.Root(), "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return Window{}, err
+	}
+	ids := uint32s(value)
+	if len(ids) == 0 || ids[0] == 0 {
+		return Window{}, errors.New("no window has the focus")
+	}
+	return Window{ID: uint64(ids[0]), Title: e.title(ids[0]), Process: e.class(ids[0])}, nil
+}
+
+func (e ewmh) Focus(id uint64) error {
+	// source 2 says the request comes from a pager, which window managers don't second-guess
+	// like they do with applications stealing focus. Mapping a minimized window is up to the WM.
+	return e.conn.ClientMessage(uint32(id), "_NET_ACTIVE_WINDOW", 2, 0, 0)
+}
+
+func (e ewmh) Minimize(id uint64) error {
+	const iconicState = 3
+	return e.conn.ClientMessage(uint32(id), "WM_CHANGE_STATE", iconicState)
+}
+
+func (e ewmh) Close(id uint64) error {
+	return e.conn.ClientMessage(uint32(id), "_NET_CLOSE_WINDOW", 0, 2)
+}
+
+// _NET_WM_STATE client message actions
+const (
+	stateRemove = 0
+	stateToggle = 2
+)
+
+// setState changes the _NET_WM_STATE of the window for up to two states.
+func (e ewmh) setState(id uint64, action uint32, states ...string) error {
+	data := []uint32{action, 0, 0, 2}
+	for i, state := range states {
+		atom, err := e.conn.Atom(state)
+		if err != nil {
+			return err
+		}
+		data[i+1] = atom
+	}
+	return e.conn.ClientMessage(uint32(id), "_NET_WM_STATE", data...)
+}
+
+func (e ewmh) ToggleMaximize(id uint64) error {
+	return e.setState(id, stateToggle, "_NET_WM_STATE_MAXIMIZED_VERT", "_NET_WM_STATE_MAXIMIZED_HORZ")
+}
+
+func (e ewmh) ToggleAlwaysOnTop(id uint64) error {
+	return e.setState(id, stateToggle, "_NET_WM_STATE_ABOVE")
+}
+
+// frameExtents returns the left, right, top and bottom size of the decorations the window manager adds.
+func (e ewmh) frameExtents(id uint64) [4]int {
+	var extents [4]int
+	value, err := e.conn.Property(uint32(id), "_NET_FRAME_EXTENTS")
+	if err != nil {
+		return extents
+	}
+	for i, v := range uint32s(value) {
+		if i < len(extents) {
+			extents[i] = int(v)
+		}
+	}
+	return extents
+}
+
+// Bounds adds the decorations to the client area X11 knows about.
+func (e ewmh) Bounds(id uint64) (image.Rectangle, error) {
+	client, err := e.conn.Geometry(uint32(id))
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	extents := e.frameExtents(id)
+	return image.Rect(client.Min.X-extents[0], client.Min.Y-extents[2], client.Max.X+extents[1], client.Max.Y+extents[3]), nil
+}
+
+func (e ewmh) Move(id uint64, bounds image.Rectangle) error {
+	if e.hasAtom(uint32(id), "_NET_WM_STATE", "_NET_WM_STATE_MAXIMIZED_VERT") || e.hasAtom(uint32(id), "_NET_WM_STATE", "_NET_WM_STATE_MAXIMIZED_HORZ") {
+		if err := e.setState(id, stateRemove, "_NET_WM_STATE_MAXIMIZED_VERT", "_NET_WM_STATE_MAXIMIZED_HORZ"); err != nil {
+			return err
+		}
+	}
+	// with north west gravity the position is the frame's corner, while the size is the client's
+	extents := e.frameExtents(id)
+	width := max(bounds.Dx()-extents[0]-extents[1], 1)
+	height := max(bounds.Dy()-extents[2]-extents[3], 1)
+	const (
+		northWestGravity = 1
+		allFields        = 0xf << 8
+		fromPager        = 2 << 12
+	)
+	return e.conn.ClientMessage(uint32(id), "_NET_MOVERESIZE_WINDOW", northWestGravity|allFields|fromPager,
+		uint32(int32(bounds.Min.X)), uint32(int32(bounds.Min.Y)), uint32(width), uint32(height))
+}
+
+// WorkAreas narrows each monitor to the _NET_WORKAREA of the current desktop. That is a single
+// rectangle around all monitors, so it only accounts for panels along the outer edges.
+func (e ewmh) WorkAreas() ([]image.Rectangle, error) {
+	monitors, err := e.conn.Monitors()
+	if err != nil {
+		return nil, err
+	}
+	workArea := image.Rectangle{}
+	if value, err := e.conn.Property(e.conn.Root(), "_NET_WORKAREA"); err == nil {
+		areas := uint32s(value)
+		desktop := 0
+		if current, err := e.conn.Property(e.conn.Root(), "_NET_CURRENT_DESKTOP"); err == nil && len(uint32s(current)) > 0 {
+			desktop = int(uint32s(current)[0])
+		}
+		if len(areas) >= (desktop+1)*4 {
+			a := areas[desktop*4:]
+			workArea = image.Rect(int(int32(a[0])), int(int32(a[1])), int(int32(a[0]+a[2])), int(int32(a[1]+a[3])))
+		}
+	}
+
+	var areas []image.Rectangle
+	for _, monitor := range monitors {
+		if area := monitor.Intersect(workArea); !area.Empty() {
+			monitor = area
+		}
+		areas = append(areas, monitor)
+	}
+	return areas, nil
+}
+
+// terminate asks a process to exit, it is replaced in tests.
+var terminate = func(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(syscall.SIGTERM)
+}
+
+// KillProcess ends the process named by _NET_WM_PID, which is only meaningful when it runs on this machine.
+func (e ewmh) KillProcess(id uint64) error {
+	value, err := e.conn.Property(uint32(id), "_NET_WM_PID")
+	if err != nil {
+		return err
+	}
+	pids := uint32s(value)
+	if len(pids) == 0 || pids[0] == 0 {
+		return errors.New("the window doesn't say which process owns it")
+	}
+	if machine, err := e.conn.Property(uint32(id), "WM_CLIENT_MACHINE"); err == nil && len(machine) > 0 {
+		if host, err := os.Hostname(); err == nil && string(machine) != host {
+			return fmt.Errorf("the window belongs to a process on %s", machine)
+		}
+	}
+	return terminate(int(pids[0]))
+}
+
+func uint32s(value []byte) []uint32 {
+	values := make([]uint32, 0, len(value)/4)
+	for i := 0; i+4 <= len(value); i += 4 {
+		values = append(values, binary.LittleEndian.Uint32(value[i:]))
+	}
+	return values
+}
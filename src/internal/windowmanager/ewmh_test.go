@@ -0,0 +1,265 @@
+package windowmanager
+
+import (
+	"encoding/binary"
+	"image"
+	"slices"
+	"testing"
+)
+
+type clientMessage struct {
+	window      uint32
+	messageType string
+	data        []uint32
+}
+
+type fakeConn struct {
+	atoms      map[string]uint32
+	properties map[uint32]map[string][]byte
+	sent       []clientMessage
+	geometry   map[uint32]image.Rectangle
+	monitors   []image.Rectangle
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{atoms: map[string]uint32{}, properties: map[uint32]map[string][]byte{}}
+}
+
+func (c *fakeConn) Root() uint32 { return 1 }
+
+func (c *fakeConn) Atom(name string) (uint32, error) {
+	if _, ok := c.atoms[name]; !ok {
+		c.atoms[name] = uint32(100 + len(c.atoms))
+	}
+	return c.atoms[name], nil
+}
+
+func (c *fakeConn) Property(window uint32, name string) ([]byte, error) {
+	return c.properties[window][name], nil
+}
+
+func (c *fakeConn) ClientMessage(window uint32, messageType string, data ...uint32) error {
+	c.sent = append(c.sent, clientMessage{window, messageType, data})
+	return nil
+}
+
+func (c *fakeConn) Geometry(window uint32) (image.Rectangle, error) {
+	return c.geometry[window], nil
+}
+
+func (c *fakeConn) Monitors() ([]image.Rectangle, error) { return c.monitors, nil }
+
+func (c *fakeConn) set(window uint32, name string, value []byte) {
+	if c.properties[window] == nil {
+		c.properties[window] = map[string][]byte{}
+	}
+	c.properties[window][name] = value
+}
+
+func (c *fakeConn) setAtoms(window uint32, name string, atoms ...string) {
+	var ids []uint32
+	for _, atom := range atoms {
+		id, _ := c.Atom(atom)
+		ids = append(ids, id)
+	}
+	c.set(window, name, encode(ids...))
+}
+
+func encode(values ...uint32) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func TestListSkipsPanelsAndUntitledWindows(t *testing.T) {
+	conn := newFakeConn()
+	conn.set(1, "_NET_CLIENT_LIST", encode(10, 11, 12, 13, 14))
+	conn.set(10, "_NET_WM_NAME", []byte("Terminal — ~"))
+	conn.set(10, "WM_CLASS", []byte("gnome-terminal-server\x00Gnome-terminal\x00"))
+	conn.set(11, "WM_NAME", []byte("xterm"))
+	conn.set(12, "_NET_WM_NAME", []byte("Panel"))
+	conn.setAtoms(12, "_NET_WM_WINDOW_TYPE", "_NET_WM_WINDOW_TYPE_DOCK")
+	conn.set(13, "_NET_WM_NAME", []byte("Tray helper"))
+	conn.setAtoms(13, "_NET_WM_STATE", "_NET_WM_STATE_ABOVE", "_NET_WM_STATE_SKIP_TASKBAR")
+	conn.setAtoms(14, "_NET_WM_WINDOW_TYPE", "_NET_WM_WINDOW_TYPE_NORMAL")
+
+	windows, err := ewmh{conn: conn}.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Window{{ID: 10, Title: "Terminal — ~", Process: "gnome-terminal-server"}, {ID: 11, Title: "xterm"}}
+	if !slices.Equal(windows, want) {
+		t.Fatalf("got %v, want %v", windows, want)
+	}
+}
+
+func TestListWithoutEWMHIsUnsupported(t *testing.T) {
+	if _, err := (ewmh{conn: newFakeConn()}).List(); err == nil {
+		t.Fatal("expected an error when _NET_CLIENT_LIST is missing")
+	}
+}
+
+func TestWindowActionsSendClientMessages(t *testing.T) {
+	conn := newFakeConn()
+	manager := ewmh{conn: conn}
+	_ = manager.Focus(10)
+	_ = manager.Minimize(11)
+	_ = manager.Close(12)
+
+	want := []clientMessage{
+		{10, "_NET_ACTIVE_WINDOW", []uint32{2, 0, 0}},
+		{11, "WM_CHANGE_STATE", []uint32{3}},
+		{12, "_NET_CLOSE_WINDOW", []uint32{0, 2}},
+	}
+	if len(conn.sent) != len(want) {
+		t.Fatalf("sent %v", conn.sent)
+	}
+	for i := range want {
+		if conn.sent[i].window != want[i].window || conn.sent[i].messageType != want[i].messageType || !slices.Equal(conn.sent[i].data, want[i].data) {
+			t.Errorf("message %d = %+v, want %+v", i, conn.sent[i], want[i])
+		}
+	}
+}
+
+func TestActiveWindow(t *testing.T) {
+	conn := newFakeConn()
+	if _, err := (ewmh{conn: conn}).Active(); err == nil {
+		t.Error("expected an error without _NET_ACTIVE_WINDOW")
+	}
+
+	conn.set(1, "_NET_ACTIVE_WINDOW", encode(10))
+	conn.set(10, "_NET_WM_NAME", []byte("Terminal"))
+	window, err := ewmh{conn: conn}.Active()
+	if err != nil || window != (Window{ID: 10, Title: "Terminal"}) {
+		t.Fatalf("got %+v %v", window, err)
+	}
+}
+
+func TestListFollowsStackingOrder(t *testing.T) {
+	conn := newFakeConn()
+	conn.set(1, "_NET_CLIENT_LIST", encode(10, 11, 12))
+	// bottom to top, so 12 was used last
+	conn.set(1, "_NET_CLIENT_LIST_STACKING", encode(11, 10, 12))
+	conn.set(10, "_NET_WM_NAME", []byte("Editor"))
+	conn.set(11, "_NET_WM_NAME", []byte("Browser"))
+	conn.set(12, "_NET_WM_NAME", []byte("Terminal"))
+
+	windows, err := ewmh{conn: conn}.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint64
+	for _, w := range windows {
+		ids = append(ids, w.ID)
+	}
+	if want := []uint64{12, 10, 11}; !slices.Equal(ids, want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+}
+
+func TestIconPicksTheSizeNearest32(t *testing.T) {
+	conn := newFakeConn()
+	pixels := func(size int, argb uint32) []uint32 {
+		p := []uint32{uint32(size), uint32(size)}
+		for range size * size {
+			p = append(p, argb)
+		}
+		return p
+	}
+	data := append(pixels(16, 0xff0000ff), pixels(32, 0x80ff0000)...)
+	data = append(data, pixels(48, 0xff00ff00)...)
+	conn.set(10, "_NET_WM_ICON", encode(data...))
+
+	icon := ewmh{conn: conn}.Icon(10)
+	if icon == nil {
+		t.Fatal("no icon")
+	}
+	if size := icon.Bounds().Size(); size.X != 32 || size.Y != 32 {
+		t.Fatalf("picked a %v icon", size)
+	}
+	if r, g, b, a := icon.At(0, 0).RGBA(); r>>8 != 0x80 || g != 0 || b != 0 || a>>8 != 0x80 {
+		t.Errorf("pixel = %x %x %x %x, want half-transparent red", r, g, b, a)
+	}
+
+	// a property cut off in the middle of the first image has no usable icon
+	conn.set(11, "_NET_WM_ICON", encode(pixels(16, 0)[:100]...))
+	if icon := (ewmh{conn: conn}).Icon(11); icon != nil {
+		t.Errorf("got an icon from a truncated property")
+	}
+}
+
+func TestMoveRestoresAndAccountsForTheFrame(t *testing.T) {
+	conn := newFakeConn()
+	conn.setAtoms(10, "_NET_WM_STATE", "_NET_WM_STATE_MAXIMIZED_VERT", "_NET_WM_STATE_MAXIMIZED_HORZ")
+	conn.set(10, "_NET_FRAME_EXTENTS", encode(2, 2, 30, 2))
+	conn.geometry = map[uint32]image.Rectangle{10: image.Rect(102, 130, 902, 730)}
+	manager := ewmh{conn: conn}
+
+	if bounds, _ := manager.Bounds(10); bounds != image.Rect(100, 100, 904, 732) {
+		t.Errorf("Bounds = %v", bounds)
+	}
+	if err := manager.Move(10, image.Rect(0, 0, 960, 1080)); err != nil {
+		t.Fatal(err)
+	}
+	vert, _ := conn.Atom("_NET_WM_STATE_MAXIMIZED_VERT")
+	horz, _ := conn.Atom("_NET_WM_STATE_MAXIMIZED_HORZ")
+	want := []clientMessage{
+		{10, "_NET_WM_STATE", []uint32{0, vert, horz, 2}},
+		{10, "_NET_MOVERESIZE_WINDOW", []uint32{1 | 0xf<<8 | 2<<12, 0, 0, 956, 1048}},
+	}
+	if len(conn.sent) != len(want) {
+		t.Fatalf("sent %v", conn.sent)
+	}
+	for i := range want {
+		if conn.sent[i].messageType != want[i].messageType || !slices.Equal(conn.sent[i].data, want[i].data) {
+			t.Errorf("message %d = %+v, want %+v", i, conn.sent[i], want[i])
+		}
+	}
+}
+
+func TestWorkAreasLeaveOutPanels(t *testing.T) {
+	conn := newFakeConn()
+	conn.monitors = []image.Rectangle{image.Rect(0, 0, 1920, 1080), image.Rect(1920, 0, 3840, 1080)}
+	// a 40 pixel panel at the top of desktop 1
+	conn.set(1, "_NET_WORKAREA", encode(0, 0, 3840, 1080, 0, 40, 3840, 1040))
+	conn.set(1, "_NET_CURRENT_DESKTOP", encode(1))
+
+	areas, err := ewmh{conn: conn}.WorkAreas()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []image.Rectangle{image.Rect(0, 40, 1920, 1080), image.Rect(1920, 40, 3840, 1080)}
+	if !slices.Equal(areas, want) {
+		t.Fatalf("got %v, want %v", areas, want)
+	}
+}
+
+func TestKillProcessSignalsTheWindowsPID(t *testing.T) {
+	var killed []int
+	defer func(original func(int) error) { terminate = original }(terminate)
+	terminate = func(pid int) error {
+		killed = append(killed, pid)
+		return nil
+	}
+
+	conn := newFakeConn()
+	conn.set(10, "_NET_WM_PID", encode(4242))
+	conn.set(11, "_NET_WM_PID", encode(4343))
+	conn.set(11, "WM_CLIENT_MACHINE", []byte("some-other-host.invalid"))
+	manager := ewmh{conn: conn}
+
+	if err := manager.KillProcess(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.KillProcess(11); err == nil {
+		t.Error("killed a process of another machine")
+	}
+	if err := manager.KillProcess(12); err == nil {
+		t.Error("expected an error without _NET_WM_PID")
+	}
+	if !slices.Equal(killed, []int{4242}) {
+		t.Errorf("killed %v", killed)
+	}
+}
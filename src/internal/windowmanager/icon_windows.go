@@ -0,0 +1,158 @@
+//go:build windows
+
+package windowmanager
+
+import (
+	"image"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	wmGetIcon        = 0x007F
+	iconSmall        = 0
+	iconSmall2       = 2
+	smtoAbortIfHung  = 0x0002
+	gclpHIconSm      = -34
+	gclpHIcon        = -14
+	biRGB            = 0
+	dibRGBColors     = 0
+	iconReplyTimeout = 50 // milliseconds
+)
+
+var (
+	gdi32                  = windows.NewLazySystemDLL("gdi32.dll")
+	procSendMessageTimeout = user32.NewProc("SendMessageTimeoutW")
+	procGetClassLongPtr    = user32.NewProc("GetClassLongPtrW")
+	procGetClassLong       = user32.NewProc("GetClassLongW")
+	procGetIconInfo        = user32.NewProc("GetIconInfo")
+	procGetObject          = gdi32.NewProc("GetObjectW")
+	procCreateCompatibleDC = gdi32.NewProc("CreateCompatibleDC")
+	procDeleteDC           = gdi32.NewProc("DeleteDC")
+	procDeleteObject       = gdi32.NewProc("DeleteObject")
+	procGetDIBits          = gdi32.NewProc("GetDIBits")
+)
+
+type iconInfo struct {
+	fIcon    int32
+	xHotspot uint32
+	yHotspot uint32
+	hbmMask  uintptr
+	hbmColor uintptr
+}
+
+type bitmap struct {
+	bmType       int32
+	bmWidth      int32
+	bmHeight     int32
+	bmWidthBytes int32
+	bmPlanes     uint16
+	bmBitsPixel  uint16
+	bmBits       uintptr
+}
+
+type bitmapInfoHeader struct {
+	biSize          uint32
+	biWidth         int32
+	biHeight        int32
+	biPlanes        uint16
+	biBitCount      uint16
+	biCompression   uint32
+	biSizeImage     uint32
+	biXPelsPerMeter int32
+	biYPelsPerMeter int32
+	biClrUsed       uint32
+	biClrImportant  uint32
+}
+
+// windowIcon returns the small icon of the window, or nil when it has none.
+func windowIcon(hwnd uintptr) image.Image {
+	icon := sendGetIcon(hwnd, iconSmall2)
+	if icon == 0 {
+		icon = sendGetIcon(hwnd, iconSmall)
+	}
+	if icon == 0 {
+		icon = classIcon(hwnd, gclpHIconSm)
+	}
+	if icon == 0 {
+		icon = classIcon(hwnd, gclpHIcon)
+	}
+	if icon == 0 {
+		return nil
+	}
+	return iconImage(icon)
+}
+
+// sendGetIcon asks the window for its icon, giving up quickly on windows that don't respond.
+func sendGetIcon(hwnd uintptr, kind uintptr) uintptr {
+	var icon uintptr
+	ret, _, _ := procSendMessageTimeout.Call(hwnd, wmGetIcon, kind, 0, smtoAbortIfHung, iconReplyTimeout, uintptr(unsafe.Pointer(&icon)))
+	if ret == 0 {
+		return 0
+	}
+	return icon
+}
+
+func classIcon(hwnd uintptr, index int32) uintptr {
+	// GetClassLongPtrW only exists in 64-bit user32
+	proc := procGetClassLongPtr
+	if proc.Find() != nil {
+		proc = procGetClassLong
+	}
+	icon, _, _ := proc.Call(hwnd, uintptr(index))
+	return icon
+}
+
+// iconImage copies the pixels of an icon into an image. The icon itself belongs to the window and isn't destroyed.
+func iconImage(icon uintptr) image.Image {
+	var info iconInfo
+	if ret, _, _ := procGetIconInfo.Call(icon, uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return nil
+	}
+	defer procDeleteObject.Call(info.hbmMask)
+	if info.hbmColor == 0 {
+		// monochrome icons keep both halves in the mask, they are rare enough to go without
+		return nil
+	}
+	defer procDeleteObject.Call(info.hbmColor)
+
+	var bm bitmap
+	if ret, _, _ := procGetObject.Call(info.hbmColor, unsafe.Sizeof(bm), uintptr(unsafe.Pointer(&bm))); ret == 0 || bm.bmWidth <= 0 || bm.bmHeight <= 0 {
+		return nil
+	}
+	dc, _, _ := procCreateCompatibleDC.Call(0)
+	if dc == 0 {
+		return nil
+	}
+	defer procDeleteDC.Call(dc)
+
+	width, height := int(bm.bmWidth), int(bm.bmHeight)
+	header := bitmapInfoHeader{
+		biWidth:       bm.bmWidth,
+		biHeight:      -bm.bmHeight, // top-down rows
+		biPlanes:      1,
+		biBitCount:    32,
+		biCompression: biRGB,
+	}
+	header.biSize = uint32(unsafe.Sizeof(header))
+	pixels := make([]byte, width*height*4)
+	if ret, _, _ := procGetDIBits.Call(dc, info.hbmColor, 0, uintptr(height), uintptr(unsafe.Pointer(&pixels[0])), uintptr(unsafe.Pointer(&header)), dibRGBColors); ret == 0 {
+		return nil
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for i := 0; i < len(pixels); i += 4 {
+		// BGRA to RGBA
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = pixels[i+2], pixels[i+1], pixels[i], pixels[i+3]
+		hasAlpha = hasAlpha || pixels[i+3] != 0
+	}
+	if !hasAlpha {
+		// old icons without an alpha channel rely on the mask, showing them opaque is close enough
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img
+}
@@ -0,0 +1,43 @@
+package windowmanager
+
+import (
+	"errors"
+	"image"
+)
+
+// Window is a top-level window of another application. ID is the HWND on Windows and the X11 window id elsewhere.
+// Process names the owning program: the executable without its extension on Windows,
+// the WM_CLASS instance name on X11. It is empty when that can't be found out.
+type Window struct {
+	ID      uint64
+	Title   string
+	Process string
+}
+
+// WindowManager lists and controls the windows the user can switch to.
+type WindowManager interface {
+	// List returns the windows in stacking order, the most recently used first.
+	List() ([]Window, error)
+	// Active returns the window that has the keyboard focus.
+	Active() (Window, error)
+	Focus(id uint64) error
+	Minimize(id uint64) error
+	Close(id uint64) error
+	// ToggleMaximize maximizes the window, or restores it when it is maximized.
+	ToggleMaximize(id uint64) error
+	ToggleAlwaysOnTop(id uint64) error
+	// Bounds returns the visible frame of the window in desktop coordinates.
+	Bounds(id uint64) (image.Rectangle, error)
+	// Move restores the window if it is maximized and gives its frame the bounds.
+	Move(id uint64, bounds image.Rectangle) error
+	// WorkAreas lists the usable part of each monitor, without taskbars and panels.
+	WorkAreas() ([]image.Rectangle, error)
+	// KillProcess ends the process that owns the window.
+	KillProcess(id uint64) error
+	// Icon returns the small icon of the window, nil when it has none. Windows that don't
+	// respond can hold it up, so List leaves icons out and only the switcher asks.
+	Icon(id uint64) image.Image
+}
+
+// ErrUnsupported is returned by New when the desktop can't be controlled, such as a Wayland session without XWayland.
+var ErrUnsupported = errors.New("window switching is not supported on this desktop")
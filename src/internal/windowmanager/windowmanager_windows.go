@@ -0,0 +1,302 @@
+//go:build windows
+
+package windowmanager
+
+import (
+	"errors"
+	"image"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	swMinimize       = 6
+	swRestore        = 9
+	wmClose          = 0x0010
+	gwlExStyle       = -20
+	gwOwner          = 4
+	wsExToolWindow   = 0x00000080
+	wsExAppWindow    = 0x00040000
+	dwmwaCloaked     = 14
+	swMaximize       = 3
+	wsExTopmost      = 0x00000008
+	swpNoSize        = 0x0001
+	swpNoMove        = 0x0002
+	swpNoZOrder      = 0x0004
+	swpNoActivate    = 0x0010
+	dwmwaFrameBounds = 9
+)
+
+var (
+	user32                  = windows.NewLazySystemDLL("user32.dll")
+	procEnumWindows         = user32.NewProc("EnumWindows")
+	procGetWindowTextLength = user32.NewProc("GetWindowTextLengthW")
+	procGetWindowText       = user32.NewProc("GetWindowTextW")
+	procIsWindowVisible     = user32.NewProc("IsWindowVisible")
+	procShowWindow          = user32.NewProc("ShowWindow")
+	procIsIconic            = user32.NewProc("IsIconic")
+	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
+	procGetForegroundWindow = user32.NewProc("GetForegroundWindow")
+	procPostMessage         = user32.NewProc("PostMessageW")
+	procGetWindowLong       = user32.NewProc("GetWindowLongW")
+	procGetWindow           = user32.NewProc("GetWindow")
+	procIsZoomed            = user32.NewProc("IsZoomed")
+	procSetWindowPos        = user32.NewProc("SetWindowPos")
+	procGetWindowRect       = user32.NewProc("GetWindowRect")
+	procEnumDisplayMonitors = user32.NewProc("EnumDisplayMonitors")
+	procGetMonitorInfo      = user32.NewProc("GetMonitorInfoW")
+
+	dwmapi                    = windows.NewLazySystemDLL("dwmapi.dll")
+	procDwmGetWindowAttribute = dwmapi.NewProc("DwmGetWindowAttribute")
+)
+
+type win32 struct{}
+
+// syscall.NewCallback never frees a callback, so the enumeration callbacks are made once and
+// collect into these while enumMu is held.
+var (
+	enumMu       sync.Mutex
+	enumWindows  []Window
+	enumAreas    []image.Rectangle
+	windowsFound = syscall.NewCallback(func(hwnd uintptr, lparam uintptr) uintptr {
+		if isSwitchable(hwnd) {
+			if title := getWindowText(hwnd); len(title) > 0 {
+				enumWindows = append(enumWindows, Window{ID: uint64(hwnd), Title: title, Process: processName(hwnd)})
+			}
+		}
+		return 1
+	})
+	monitorFound = syscall.NewCallback(func(monitor, dc, clip, lparam uintptr) uintptr {
+		info := monitorInfo{size: uint32(unsafe.Sizeof(monitorInfo{}))}
+		if ret, _, _ := procGetMonitorInfo.Call(monitor, uintptr(unsafe.Pointer(&info))); ret != 0 {
+			enumAreas = append(enumAreas, rectangle(info.work))
+		}
+		return 1
+	})
+)
+
+// New returns the Win32 window manager.
+func New() (WindowManager, error) { return win32{}, nil }
+
+// List enumerates the windows Alt+Tab would show. EnumWindows goes through them in Z order,
+// which puts the window used last on top.
+func (win32) List() ([]Window, error) {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	enumWindows = nil
+	_, _, _ = procEnumWindows.Call(windowsFound, 0)
+	windows := enumWindows
+	enumWindows = nil
+	return windows, nil
+}
+
+func (win32) Icon(id uint64) image.Image { return windowIcon(uintptr(id)) }
+
+func (win32) Active() (Window, error) {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return Window{}, errors.New("no window has the focus")
+	}
+	return Window{ID: uint64(hwnd), Title: getWindowText(hwnd), Process: processName(hwnd)}, nil
+}
+
+func (win32) Focus(id uint64) error {
+	// only restore if minimized
+	if isWindowMinimized(uintptr(id)) {
+		_, _, _ = procShowWindow.Call(uintptr(id), swRestore)
+	}
+	// fails without an error code when Windows' foreground lock refuses it, the window still flashes
+	_, _, _ = procSetForegroundWindow.Call(uintptr(id))
+	return nil
+}
+
+func (win32) Minimize(id uint64) error {
+	_, _, _ = procShowWindow.Call(uintptr(id), swMinimize)
+	return nil
+}
+
+func (win32) Close(id uint64) error {
+	if ret, _, err := procPostMessage.Call(uintptr(id), wmClose, 0, 0); ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func (win32) ToggleMaximize(id uint64) error {
+	if isZoomed(uintptr(id)) {
+		_, _, _ = procShowWindow.Call(uintptr(id), swRestore)
+	} else {
+		_, _, _ = procShowWindow.Call(uintptr(id), swMaximize)
+	}
+	return nil
+}
+
+func (win32) ToggleAlwaysOnTop(id uint64) error {
+	index := int32(gwlExStyle)
+	exStyle, _, _ := procGetWindowLong.Call(uintptr(id), uintptr(index))
+	// HWND_TOPMOST, or HWND_NOTOPMOST to switch it off
+	insertAfter := int64(-1)
+	if exStyle&wsExTopmost != 0 {
+		insertAfter = -2
+	}
+	if ret, _, err := procSetWindowPos.Call(uintptr(id), uintptr(insertAfter), 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate); ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// Bounds returns the frame DWM draws, which leaves out the invisible resize borders
+// GetWindowRect includes since Windows 10.
+func (win32) Bounds(id uint64) (image.Rectangle, error) {
+	frame, _, err := frameBounds(uintptr(id))
+	return frame, err
+}
+
+func (win32) Move(id uint64, bounds image.Rectangle) error {
+	hwnd := uintptr(id)
+	if isZoomed(hwnd) {
+		_, _, _ = procShowWindow.Call(hwnd, swRestore)
+	}
+	frame, outer, err := frameBounds(hwnd)
+	if err != nil {
+		return err
+	}
+	// grow the target by the invisible borders so the visible frame lands on bounds
+	bounds.Min = bounds.Min.Sub(frame.Min.Sub(outer.Min))
+	bounds.Max = bounds.Max.Add(outer.Max.Sub(frame.Max))
+	x, y := int64(bounds.Min.X), int64(bounds.Min.Y)
+	if ret, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), uintptr(bounds.Dx()), uintptr(bounds.Dy()), swpNoZOrder|swpNoActivate); ret == 0 {
+		return err
+	}
+	return nil
+}
+
+type monitorInfo struct {
+	size    uint32
+	monitor windows.Rect
+	work    windows.Rect
+	flags   uint32
+}
+
+func (win32) WorkAreas() ([]image.Rectangle, error) {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	enumAreas = nil
+	ret, _, err := procEnumDisplayMonitors.Call(0, 0, monitorFound, 0)
+	areas := enumAreas
+	enumAreas = nil
+	if ret == 0 {
+		return nil, err
+	}
+	return areas, nil
+}
+
+func (win32) KillProcess(id uint64) error {
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(windows.HWND(id), &pid); err != nil {
+		return err
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, pid)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(process)
+	return windows.TerminateProcess(process, 1)
+}
+
+// frameBounds returns the visible frame of the window and the rectangle GetWindowRect reports for it.
+func frameBounds(hwnd uintptr) (frame, outer image.Rectangle, err error) {
+	var rect windows.Rect
+	if ret, _, err := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect))); ret == 0 {
+		return image.Rectangle{}, image.Rectangle{}, err
+	}
+	outer = rectangle(rect)
+	var extended windows.Rect
+	if ret, _, _ := procDwmGetWindowAttribute.Call(hwnd, dwmwaFrameBounds, uintptr(unsafe.Pointer(&extended)), unsafe.Sizeof(extended)); ret != 0 {
+		// without DWM composition there are no invisible borders
+		return outer, outer, nil
+	}
+	return rectangle(extended), outer, nil
+}
+
+func rectangle(r windows.Rect) image.Rectangle {
+	return image.Rect(int(r.Left), int(r.Top), int(r.Right), int(r.Bottom))
+}
+
+func isZoomed(hwnd uintptr) bool {
+	ret, _, _ := procIsZoomed.Call(hwnd)
+	return ret != 0
+}
+
+func isWindowMinimized(hwnd uintptr) bool {
+	ret, _, _ := procIsIconic.Call(hwnd)
+	return ret != 0
+}
+
+func getWindowText(hwnd uintptr) string {
+	length, _, _ := procGetWindowTextLength.Call(hwnd)
+
+	if length == 0 {
+		return ""
+	}
+
+	buf := make([]uint16, length+1)
+	_, _, _ = procGetWindowText.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), length+1)
+	return syscall.UTF16ToString(buf)
+}
+
+// isSwitchable leaves out hidden windows, tool windows and dialogs owned by another window
+// unless they ask for a taskbar button, and windows cloaked on another virtual desktop or
+// suspended as UWP apps are.
+func isSwitchable(hwnd uintptr) bool {
+	if !isWindowVisible(hwnd) {
+		return false
+	}
+	index := int32(gwlExStyle)
+	exStyle, _, _ := procGetWindowLong.Call(hwnd, uintptr(index))
+	if exStyle&wsExAppWindow == 0 {
+		if exStyle&wsExToolWindow != 0 {
+			return false
+		}
+		if owner, _, _ := procGetWindow.Call(hwnd, gwOwner); owner != 0 {
+			return false
+		}
+	}
+	var cloaked uint32
+	if ret, _, _ := procDwmGetWindowAttribute.Call(hwnd, dwmwaCloaked, uintptr(unsafe.Pointer(&cloaked)), unsafe.Sizeof(cloaked)); ret == 0 && cloaked != 0 {
+		return false
+	}
+	return true
+}
+
+func isWindowVisible(hwnd uintptr) bool {
+	ret, _, _ := procIsWindowVisible.Call(hwnd)
+	return ret != 0
+}
+
+// processName returns the executable name of the process owning the window, without ".exe".
+func processName(hwnd uintptr) string {
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(windows.HWND(hwnd), &pid); err != nil || pid == 0 {
+		return ""
+	}
+	// limited information is enough for the image name and is granted for elevated processes too
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(process)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err = windows.QueryFullProcessImageName(process, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	name := filepath.Base(windows.UTF16ToString(buf[:size]))
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
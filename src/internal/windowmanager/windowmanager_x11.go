@@ -0,0 +1,115 @@
+//go:build !windows
+
+package windowmanager
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xinerama"
+	"github.com/jezek/xgb/xproto"
+)
+
+// New connects to the X server in $DISPLAY. On Wayland this reaches XWayland, which only knows X11 clients.
+func New() (WindowManager, error) {
+	if os.Getenv("DISPLAY") == "" {
+		return nil, ErrUnsupported
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return ewmh{conn: &x11Conn{conn: conn, root: xproto.Setup(conn).DefaultScreen(conn).Root, atoms: map[string]xproto.Atom{}}}, nil
+}
+
+type x11Conn struct {
+	conn *xgb.Conn
+	root xproto.Window
+
+	mu    sync.Mutex
+	atoms map[string]xproto.Atom
+	// the result of initializing the Xinerama extension, nil until it was tried
+	xinerama *error
+}
+
+func (c *x11Conn) Root() uint32 { return uint32(c.root) }
+
+func (c *x11Conn) Atom(name string) (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if atom, ok := c.atoms[name]; ok {
+		return uint32(atom), nil
+	}
+	reply, err := xproto.InternAtom(c.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, err
+	}
+	c.atoms[name] = reply.Atom
+	return uint32(reply.Atom), nil
+}
+
+func (c *x11Conn) Property(window uint32, name string) ([]byte, error) {
+	atom, err := c.Atom(name)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := xproto.GetProperty(c.conn, false, xproto.Window(window), xproto.Atom(atom), xproto.GetPropertyTypeAny, 0, 1<<16).Reply()
+	if err != nil {
+		return nil, err
+	}
+	return reply.Value, nil
+}
+
+func (c *x11Conn) ClientMessage(window uint32, messageType string, data ...uint32) error {
+	atom, err := c.Atom(messageType)
+	if err != nil {
+		return err
+	}
+	event := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: xproto.Window(window),
+		Type:   xproto.Atom(atom),
+		Data:   xproto.ClientMessageDataUnionData32New(append(data, make([]uint32, 5)...)[:5]),
+	}
+	mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+	return xproto.SendEventChecked(c.conn, false, c.root, mask, string(event.Bytes())).Check()
+}
+
+func (c *x11Conn) Geometry(window uint32) (image.Rectangle, error) {
+	geometry, err := xproto.GetGeometry(c.conn, xproto.Drawable(window)).Reply()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	// the position is relative to the frame the window manager reparented the window into
+	origin, err := xproto.TranslateCoordinates(c.conn, xproto.Window(window), c.root, 0, 0).Reply()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	x, y := int(origin.DstX), int(origin.DstY)
+	return image.Rect(x, y, x+int(geometry.Width), y+int(geometry.Height)), nil
+}
+
+// Monitors asks Xinerama, which RandR servers also answer, and falls back to the whole screen.
+func (c *x11Conn) Monitors() ([]image.Rectangle, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.xinerama == nil {
+		err := xinerama.Init(c.conn)
+		c.xinerama = &err
+	}
+	if *c.xinerama == nil {
+		if reply, err := xinerama.QueryScreens(c.conn).Reply(); err == nil && len(reply.ScreenInfo) > 0 {
+			var monitors []image.Rectangle
+			for _, screen := range reply.ScreenInfo {
+				x, y := int(screen.XOrg), int(screen.YOrg)
+				monitors = append(monitors, image.Rect(x, y, x+int(screen.Width), y+int(screen.Height)))
+			}
+			return monitors, nil
+		}
+	}
+	screen := xproto.Setup(c.conn).DefaultScreen(c.conn)
+	return []image.Rectangle{image.Rect(0, 0, int(screen.WidthInPixels), int(screen.HeightInPixels))}, nil
+}
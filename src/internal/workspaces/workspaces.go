@@ -0,0 +1,350 @@
+package workspaces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/settings"
+	"winfastnav/internal/windowmanager"
+)
+
+// Window is a window to bring back. Its position is relative to the work area of monitor
+// number Monitor, counted from the left, so a layout survives the taskbar moving.
+type Window struct {
+	Process string `json:"process"`
+	Title   string `json:"title"`
+	// Program is the command line that starts the process, empty when it isn't an indexed app.
+	Program string `json:"program,omitempty"`
+	Monitor int    `json:"monitor"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+}
+
+// Workspace is a named set of windows, and optionally the documents that were open in them.
+type Workspace struct {
+	Name      string   `json:"name"`
+	Windows   []Window `json:"windows"`
+	Documents []string `json:"documents,omitempty"`
+}
+
+// ErrNotFound is returned for a workspace name that wasn't saved.
+var ErrNotFound = errors.New("no workspace with that name")
+
+// List returns the saved workspaces.
+func List() ([]Workspace, error) {
+	unparsed, err := settings.GetSetting("workspaces")
+	if err != nil || unparsed == "" {
+		return nil, err
+	}
+	var list []Workspace
+	if err = json.Unmarshal([]byte(unparsed), &list); err != nil {
+		return nil, fmt.Errorf("invalid workspaces setting: %w", err)
+	}
+	return list, nil
+}
+
+// Find returns the workspace with the name, ignoring case.
+func Find(name string) (Workspace, error) {
+	list, err := List()
+	if err != nil {
+		return Workspace{}, err
+	}
+	for _, w := range list {
+		if strings.EqualFold(w.Name, name) {
+			return w, nil
+		}
+	}
+	return Workspace{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Save stores the workspace, replacing one with the same name.
+func Save(workspace Workspace) error {
+	workspace.Name = strings.TrimSpace(workspace.Name)
+	if workspace.Name == "" || strings.ContainsAny(workspace.Name, " \t") {
+		return fmt.Errorf("workspace name %q must be a single word", workspace.Name)
+	}
+	list, err := List()
+	if err != nil {
+		return err
+	}
+	list = slices.DeleteFunc(list, func(w Workspace) bool { return strings.EqualFold(w.Name, workspace.Name) })
+	return save(append(list, workspace))
+}
+
+// Delete removes the workspace with the name.
+func Delete(name string) error {
+	list, err := List()
+	if err != nil {
+		return err
+	}
+	kept := slices.DeleteFunc(slices.Clone(list), func(w Workspace) bool { return strings.EqualFold(w.Name, name) })
+	if len(kept) == len(list) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return save(kept)
+}
+
+func save(list []Workspace) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return settings.SetSetting("workspaces", string(data))
+}
+
+// Environment is what capturing and restoring workspaces use from the rest of the launcher.
+type Environment struct {
+	WM windowmanager.WindowManager
+	// Skip leaves windows out of workspaces, like the launcher's own.
+	Skip func(windowmanager.Window) bool
+	// Program returns the command line that starts process.
+	Program func(process string) (string, bool)
+	// Documents returns the indexed documents, to recognize the ones open in windows.
+	Documents func() []g.Resource
+	// Launch starts a program. exited is closed when it ends, nil when that isn't known.
+	Launch func(commandLine string) (exited <-chan struct{}, err error)
+	Open   func(path string) error
+	// Wait is how long to look for the windows of launched programs, to move them into place.
+	Wait time.Duration
+}
+
+// Capture records the open windows and where they are, leaving out minimized ones. With
+// documents, indexed documents named in window titles are recorded too.
+func (e Environment) Capture(name string, documents bool) (Workspace, error) {
+	workspace := Workspace{Name: name}
+	windows, err := e.windows()
+	if err != nil {
+		return workspace, err
+	}
+	areas, err := windowmanager.Monitors(e.WM)
+	if err != nil {
+		return workspace, err
+	}
+
+	var titles []string
+	for _, w := range windows {
+		if w.Process == "" {
+			// nothing to recognize it by when restoring
+			continue
+		}
+		bounds, err := e.WM.Bounds(w.ID)
+		if err != nil || !onScreen(bounds, areas) {
+			// Windows parks minimized windows far off screen, restoring them there would lose them
+			continue
+		}
+		monitor := windowmanager.MonitorOf(bounds, areas)
+		offset := bounds.Min.Sub(areas[monitor].Min)
+		saved := Window{Process: w.Process, Title: w.Title, Monitor: monitor, X: offset.X, Y: offset.Y, Width: bounds.Dx(), Height: bounds.Dy()}
+		if e.Program != nil {
+			saved.Program, _ = e.Program(w.Process)
+		}
+		workspace.Windows = append(workspace.Windows, saved)
+		titles = append(titles, w.Title)
+	}
+	if len(workspace.Windows) == 0 {
+		return workspace, errors.New("there are no windows to save")
+	}
+	if documents && e.Documents != nil {
+		workspace.Documents = DocumentsIn(titles, e.Documents())
+	}
+	return workspace, nil
+}
+
+func onScreen(bounds image.Rectangle, areas []image.Rectangle) bool {
+	for _, area := range areas {
+		if bounds.Overlaps(area) {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentsIn returns the paths of the documents whose file name appears in one of the titles,
+// which is how most editors and viewers name their windows.
+func DocumentsIn(titles []string, documents []g.Resource) []string {
+	var found []string
+	for _, doc := range documents {
+		name := strings.ToLower(filepath.Base(doc.Filepath))
+		for _, title := range titles {
+			if strings.Contains(strings.ToLower(title), name) && !slices.Contains(found, doc.Filepath) {
+				found = append(found, doc.Filepath)
+			}
+		}
+	}
+	return found
+}
+
+// Report says what Restore did.
+type Report struct {
+	Moved, Launched int
+	// Missing lists the windows that couldn't be brought back.
+	Missing []string
+}
+
+func (r Report) String() string {
+	s := fmt.Sprintf("Moved %d windows", r.Moved)
+	if r.Launched > 0 {
+		s += fmt.Sprintf(", started %d programs", r.Launched)
+	}
+	if len(r.Missing) > 0 {
+		s += ". Couldn't restore " + strings.Join(r.Missing, ", ")
+	}
+	return s + "."
+}
+
+// Restore moves open windows into the places saved in workspace, and starts the programs of the
+// windows that aren't open, moving their windows as they appear until Wait runs out or the
+// program ends.
+func (e Environment) Restore(ctx context.Context, workspace Workspace) (Report, error) {
+	var report Report
+	areas, err := windowmanager.Monitors(e.WM)
+	if err != nil {
+		return report, err
+	}
+	windows, err := e.windows()
+	if err != nil {
+		return report, err
+	}
+
+	used := make(map[uint64]bool)
+	var waiting []Window
+	for _, saved := range workspace.Windows {
+		if id, ok := match(saved, windows, used); ok {
+			if err := e.WM.Move(id, target(saved, areas)); err == nil {
+				report.Moved++
+			}
+			continue
+		}
+		waiting = append(waiting, saved)
+	}
+
+	// one launch per program, a second window of a running app is up to the app
+	launched := make(map[string]<-chan struct{})
+	for _, saved := range waiting {
+		if _, ok := launched[saved.Program]; ok {
+			continue
+		}
+		if saved.Program == "" || e.Launch == nil {
+			report.Missing = append(report.Missing, saved.Process)
+			continue
+		}
+		exited, err := e.Launch(saved.Program)
+		if err != nil {
+			report.Missing = append(report.Missing, saved.Process)
+			continue
+		}
+		launched[saved.Program] = exited
+		report.Launched++
+	}
+	waiting = slices.DeleteFunc(waiting, func(w Window) bool {
+		_, ok := launched[w.Program]
+		return !ok
+	})
+
+	for _, path := range workspace.Documents {
+		if e.Open != nil && !documentOpen(path, windows) {
+			if err := e.Open(path); err != nil {
+				report.Missing = append(report.Missing, filepath.Base(path))
+			}
+		}
+	}
+
+	deadline := time.Now().Add(e.Wait)
+	for len(waiting) > 0 && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-time.After(min(250*time.Millisecond, e.Wait)):
+		}
+		// a program that ended before this look at the windows won't open any more
+		ended := make(map[string]bool)
+		for program, exited := range launched {
+			select {
+			case <-exited:
+				ended[program] = true
+			default:
+			}
+		}
+		if windows, err = e.windows(); err != nil {
+			return report, err
+		}
+		waiting = slices.DeleteFunc(waiting, func(saved Window) bool {
+			id, ok := match(saved, windows, used)
+			if ok {
+				_ = e.WM.Move(id, target(saved, areas))
+			} else if ended[saved.Program] {
+				report.Missing = append(report.Missing, saved.Process)
+				return true
+			}
+			return ok
+		})
+	}
+	for _, saved := range waiting {
+		report.Missing = append(report.Missing, saved.Process)
+	}
+	return report, nil
+}
+
+func (e Environment) windows() ([]windowmanager.Window, error) {
+	windows, err := e.WM.List()
+	if err != nil {
+		return nil, err
+	}
+	if e.Skip != nil {
+		windows = slices.DeleteFunc(windows, e.Skip)
+	}
+	return windows, nil
+}
+
+// match finds an unused window of the saved one's process, preferring the same title, and marks it used.
+func match(saved Window, windows []windowmanager.Window, used map[uint64]bool) (uint64, bool) {
+	found, ok := uint64(0), false
+	for _, w := range windows {
+		if used[w.ID] || !strings.EqualFold(w.Process, saved.Process) {
+			continue
+		}
+		if w.Title == saved.Title {
+			found, ok = w.ID, true
+			break
+		}
+		if !ok {
+			found, ok = w.ID, true
+		}
+	}
+	if ok {
+		used[found] = true
+	}
+	return found, ok
+}
+
+// target places the saved window on its monitor, or the first one when it is no longer
+// connected or the number is out of range in a hand-edited file, shrinking it to fit.
+func target(saved Window, areas []image.Rectangle) image.Rectangle {
+	area := areas[0]
+	if saved.Monitor >= 0 && saved.Monitor < len(areas) {
+		area = areas[saved.Monitor]
+	}
+	width, height := min(saved.Width, area.Dx()), min(saved.Height, area.Dy())
+	x := min(max(area.Min.X+saved.X, area.Min.X), area.Max.X-width)
+	y := min(max(area.Min.Y+saved.Y, area.Min.Y), area.Max.Y-height)
+	return image.Rect(x, y, x+width, y+height)
+}
+
+func documentOpen(path string, windows []windowmanager.Window) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, w := range windows {
+		if strings.Contains(strings.ToLower(w.Title), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"image"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/windowmanager"
+)
+
+// fakeDesktop keeps windows in memory, moving them when asked. Launching a program adds
+// a window of its process.
+type fakeDesktop struct {
+	mu      sync.Mutex
+	windows []windowmanager.Window
+	bounds  map[uint64]image.Rectangle
+	areas   []image.Rectangle
+	started []string
+}
+
+func (d *fakeDesktop) List() ([]windowmanager.Window, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.windows), nil
+}
+func (d *fakeDesktop) Active() (windowmanager.Window, error) { return d.windows[0], nil }
+func (d *fakeDesktop) Focus(uint64) error                    { return nil }
+func (d *fakeDesktop) Minimize(uint64) error                 { return nil }
+func (d *fakeDesktop) Close(uint64) error                    { return nil }
+func (d *fakeDesktop) ToggleMaximize(uint64) error           { return nil }
+func (d *fakeDesktop) ToggleAlwaysOnTop(uint64) error        { return nil }
+func (d *fakeDesktop) KillProcess(uint64) error              { return nil }
+func (d *fakeDesktop) Icon(uint64) image.Image               { return nil }
+func (d *fakeDesktop) WorkAreas() ([]image.Rectangle, error) { return d.areas, nil }
+func (d *fakeDesktop) Bounds(id uint64) (image.Rectangle, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.bounds[id], nil
+}
+func (d *fakeDesktop) Move(id uint64, bounds image.Rectangle) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.bounds[id] = bounds
+	return nil
+}
+
+func (d *fakeDesktop) add(id uint64, process, title string, bounds image.Rectangle) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.windows = append(d.windows, windowmanager.Window{ID: id, Process: process, Title: title})
+	d.bounds[id] = bounds
+}
+
+func (d *fakeDesktop) environment() Environment {
+	return Environment{
+		WM:   d,
+		Skip: func(w windowmanager.Window) bool { return w.Title == g.AppName },
+		Program: func(process string) (string, bool) {
+			return "/usr/bin/" + process, process != "unknown"
+		},
+		Documents: func() []g.Resource {
+			return []g.Resource{{Name: "notes", Filepath: "/home/me/notes.txt"}, {Name: "budget", Filepath: "/home/me/budget.ods"}}
+		},
+		Launch: func(commandLine string) (<-chan struct{}, error) {
+			d.started = append(d.started, commandLine)
+			// the window shows up a little later
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				d.add(100, "editor", "untitled - Editor", image.Rect(0, 0, 100, 100))
+			}()
+			return nil, nil
+		},
+		Open: func(string) error { return nil },
+		Wait: time.Second,
+	}
+}
+
+func newDesktop() *fakeDesktop {
+	d := &fakeDesktop{
+		bounds: map[uint64]image.Rectangle{},
+		areas:  []image.Rectangle{image.Rect(1920, 0, 3840, 1040), image.Rect(0, 40, 1920, 1080)},
+	}
+	d.add(1, "editor", "notes.txt - Editor", image.Rect(0, 40, 960, 1080))
+	d.add(2, "browser", "Docs - Browser", image.Rect(2000, 100, 3000, 900))
+	d.add(3, "", "No process", image.Rect(0, 0, 10, 10))
+	d.add(4, "launcher", g.AppName, image.Rect(0, 0, 10, 10))
+	return d
+}
+
+func TestCaptureRecordsPositionsPerMonitor(t *testing.T) {
+	d := newDesktop()
+	// minimized on Windows
+	d.add(5, "player", "Music - Player", image.Rect(-32000, -32000, -31840, -31972))
+	workspace, err := d.environment().Capture("coding", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Window{
+		{Process: "editor", Title: "notes.txt - Editor", Program: "/usr/bin/editor", Monitor: 0, X: 0, Y: 0, Width: 960, Height: 1040},
+		{Process: "browser", Title: "Docs - Browser", Program: "/usr/bin/browser", Monitor: 1, X: 80, Y: 100, Width: 1000, Height: 800},
+	}
+	if !slices.Equal(workspace.Windows, want) {
+		t.Errorf("windows = %+v\nwant %+v", workspace.Windows, want)
+	}
+	if !slices.Equal(workspace.Documents, []string{"/home/me/notes.txt"}) {
+		t.Errorf("documents = %v", workspace.Documents)
+	}
+
+	withoutDocuments, _ := d.environment().Capture("coding", false)
+	if withoutDocuments.Documents != nil {
+		t.Errorf("captured documents without being asked: %v", withoutDocuments.Documents)
+	}
+}
+
+func TestRestoreMovesOpenWindowsAndLaunchesMissingOnes(t *testing.T) {
+	d := newDesktop()
+	workspace := Workspace{Name: "meeting", Windows: []Window{
+		{Process: "browser", Title: "Calendar - Browser", Monitor: 0, X: 0, Y: 0, Width: 960, Height: 1040},
+		{Process: "editor", Title: "notes.txt - Editor", Program: "/usr/bin/editor", Monitor: 1, X: 960, Y: 0, Width: 960, Height: 1040},
+		{Process: "editor", Title: "minutes.txt - Editor", Program: "/usr/bin/editor", Monitor: 1, X: 0, Y: 0, Width: 960, Height: 1040},
+		{Process: "unknown", Title: "Something", Monitor: 5, X: 0, Y: 0, Width: 100, Height: 100},
+	}}
+
+	report, err := d.environment().Restore(context.Background(), workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Moved != 2 || report.Launched != 1 || !slices.Equal(report.Missing, []string{"unknown"}) {
+		t.Errorf("report = %+v", report)
+	}
+	if !slices.Equal(d.started, []string{"/usr/bin/editor"}) {
+		t.Errorf("started %v", d.started)
+	}
+	for id, want := range map[uint64]image.Rectangle{
+		2:   image.Rect(0, 40, 960, 1080),
+		1:   image.Rect(2880, 0, 3840, 1040),
+		100: image.Rect(1920, 0, 2880, 1040),
+	} {
+		if got, _ := d.Bounds(id); got != want {
+			t.Errorf("window %d at %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestRestoreKeepsWindowsOfUnknownMonitorsOnScreen(t *testing.T) {
+	d := newDesktop()
+	workspace := Workspace{Windows: []Window{{Process: "browser", Title: "Docs - Browser", Monitor: -1, X: 10, Y: 10, Width: 100, Height: 100}}}
+	if _, err := d.environment().Restore(context.Background(), workspace); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := d.Bounds(2); got != image.Rect(10, 50, 110, 150) {
+		t.Errorf("window at %v", got)
+	}
+
+	d.areas = nil
+	if _, err := d.environment().Restore(context.Background(), workspace); err == nil {
+		t.Error("restored without any monitor")
+	}
+	if _, err := d.environment().Capture("none", false); err == nil {
+		t.Error("captured without any monitor")
+	}
+}
+
+func TestRestoreGivesUpOnWindowsThatNeverAppear(t *testing.T) {
+	d := newDesktop()
+	env := d.environment()
+	env.Launch = func(string) (<-chan struct{}, error) { return nil, nil }
+	env.Wait = 50 * time.Millisecond
+	workspace := Workspace{Windows: []Window{{Process: "player", Program: "/usr/bin/player", Width: 10, Height: 10}}}
+
+	report, err := env.Restore(context.Background(), workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Launched != 1 || !slices.Equal(report.Missing, []string{"player"}) {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestRestoreStopsWaitingWhenProgramExits(t *testing.T) {
+	d := newDesktop()
+	env := d.environment()
+	env.Launch = func(string) (<-chan struct{}, error) {
+		exited := make(chan struct{})
+		close(exited)
+		return exited, nil
+	}
+	env.Wait = time.Minute
+	workspace := Workspace{Windows: []Window{{Process: "player", Program: "/usr/bin/player", Width: 10, Height: 10}}}
+
+	start := time.Now()
+	report, err := env.Restore(context.Background(), workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("kept waiting %v for a program that had ended", elapsed)
+	}
+	if !slices.Equal(report.Missing, []string{"player"}) {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestSaveFindAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	if runtime.GOOS == "windows" {
+		t.Setenv("APPDATA", dir)
+	} else {
+		t.Setenv("XDG_CONFIG_HOME", dir)
+	}
+
+	if err := Save(Workspace{Name: "two words"}); err == nil {
+		t.Error("saved a workspace named with two words")
+	}
+	if err := Save(Workspace{Name: "coding", Windows: []Window{{Process: "editor"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(Workspace{Name: "Coding", Windows: []Window{{Process: "terminal"}}}); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := List()
+	if len(list) != 1 {
+		t.Fatalf("saving the same name twice kept %d workspaces", len(list))
+	}
+	found, err := Find("CODING")
+	if err != nil || found.Windows[0].Process != "terminal" {
+		t.Fatalf("Find = %+v, %v", found, err)
+	}
+	if err = Delete("coding"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Find("coding"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("found a deleted workspace: %v", err)
+	}
+	if err = Delete("coding"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete of a missing workspace = %v", err)
+	}
+}
@@ -1,19 +1,31 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"sync"
 	"winfastnav/internal/apps"
+	"winfastnav/internal/autostart"
+	"winfastnav/internal/cli"
 	"winfastnav/internal/documents"
+	g "winfastnav/internal/globals"
 	"winfastnav/internal/hotkey"
+	"winfastnav/internal/ipc"
 	"winfastnav/internal/settings"
 	"winfastnav/ui"
 )
 
-var keyboardHotkey *hotkey.Listener
+var (
+	keyboardHotkey *hotkey.Listener
+	hotkeyMu       sync.Mutex
+	// uiReady is closed once the launcher is up, requests arriving earlier wait for it
+	uiReady = make(chan struct{})
+)
 
 func main() {
 	// Setup file log for panics to try and hunt down a crash when resuming from sleep.
@@ -31,31 +43,101 @@ func main() {
 				_, _ = f.Write(debug.Stack())
 				_ = f.Close()
 			}
+			// the commands leading up to the panic, for winfastnav replay
+			if f, _ = os.Create(filepath.Join(dir, "panic-commands.json")); f != nil {
+				_ = ui.WriteHistory(f)
+				_ = f.Close()
+			}
 			log.Printf("panic: %v\n%s", r, debug.Stack())
 		}
 	}()
 
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		attachConsole()
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	requests, err := parseArgs(os.Args[1:])
+	if err != nil {
+		log.Printf("invalid arguments: %v", err)
+		os.Exit(2)
+	}
+
+	// A second invocation only forwards its request, so there's one tray icon and one set of hotkeys.
+	server, err := ipc.Listen(func(req ipc.Request) error {
+		<-uiReady
+		return handleRequest(req)
+	})
+	if errors.Is(err, ipc.ErrRunning) {
+		if err = ipc.Send(requests...); err != nil {
+			log.Printf("failed to reach the running instance: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("failed to open the control channel, continuing without it: %v", err)
+	} else {
+		defer server.Close()
+	}
+	if slices.ContainsFunc(requests, func(req ipc.Request) bool { return req.Command == ipc.CommandQuit }) {
+		return
+	}
+
 	settings.SetupSettings()
+	if err = autostart.Refresh(); err != nil {
+		log.Printf("failed to update the start at login entry: %v", err)
+	}
 	ui.SetupUI()
+	ui.OnHotkeysChanged(func() { go listenHotkeys() })
 	go documents.SetupDocs()
 	go apps.SetupApps()
 	go listenHotkeys()
 	ui.Run()
+	close(uiReady)
+	go func() {
+		for _, req := range requests {
+			if req.Command != ipc.CommandShow {
+				_ = handleRequest(req)
+			}
+		}
+	}()
 	setupTray()
 }
 
+// listenHotkeys (re-)registers the configured hotkeys. Registration problems are shown in the UI.
 func listenHotkeys() {
-	listener, err := hotkey.Start(func() {
-		ui.ShowWindow()
-	})
-	if err != nil {
-		log.Printf("failed to register Alt+O hotkey: %v", err)
-		return
+	hotkeyMu.Lock()
+	defer hotkeyMu.Unlock()
+	if keyboardHotkey != nil {
+		keyboardHotkey.Stop()
+		keyboardHotkey = nil
 	}
+
+	listener, err := hotkey.Start(hotkey.Bindings(), onHotkey)
 	keyboardHotkey = listener
+	ui.SetHotkeyError(err)
+	if err != nil {
+		log.Printf("failed to register hotkeys: %v", err)
+	}
+}
+
+func onHotkey(action hotkey.Action) {
+	switch action {
+	case hotkey.ActionShowDocuments:
+		ui.ShowWindowMode(g.ModeSearchDocument)
+	case hotkey.ActionShowWindows:
+		ui.ShowWindowMode(g.ModeChooseProgram)
+	case hotkey.ActionShowGPT:
+		ui.ShowWindowMode(g.ModeAskGPT)
+	default:
+		ui.ShowWindow()
+	}
 }
 
 func onExit() {
+	hotkeyMu.Lock()
+	defer hotkeyMu.Unlock()
 	if keyboardHotkey != nil {
 		keyboardHotkey.Stop()
 	}
@@ -3,7 +3,9 @@ package main
 import (
 	"github.com/getlantern/systray"
 	"os"
+	"sync/atomic"
 	g "winfastnav/internal/globals"
+	"winfastnav/internal/presentation"
 	"winfastnav/ui"
 )
 
@@ -21,11 +23,34 @@ func onReady() {
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Exit", "Exit program")
 
+	// the first item hides the launcher while it's shown
+	var visible atomic.Bool
+	showToggle := func(shown bool) {
+		visible.Store(shown)
+		if shown {
+			mToggle.SetTitle("Hide")
+			mToggle.SetTooltip("Hide window")
+		} else {
+			mToggle.SetTitle("Show")
+			mToggle.SetTooltip("Show window")
+		}
+	}
+	ui.Subscribe(func(change presentation.Change) {
+		if change.Has(presentation.FieldVisible) {
+			showToggle(change.After.Visible)
+		}
+	})
+	showToggle(ui.Visible())
+
 	go func() {
 		for {
 			select {
 			case <-mToggle.ClickedCh:
-				go ui.ShowWindow()
+				if visible.Load() {
+					go ui.HideWindow()
+				} else {
+					go ui.ShowWindow()
+				}
 			case <-mAbout.ClickedCh:
 				go func() {
 					ui.ShowWindow()
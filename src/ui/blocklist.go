@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/apps"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/presentation"
+)
+
+var ruleKinds = []string{g.BlockPath, g.BlockName, g.BlockGlob, g.BlockRegex}
+
+type blocklistEditor struct {
+	pattern                                widget.Editor
+	kind                                   string
+	cycleKind, add, clear, confirm, cancel widget.Clickable
+	list                                   widget.List
+	unblock                                []widget.Clickable
+}
+
+func (l *launcher) blocklistPage(gtx layout.Context) layout.Dimensions {
+	e := &l.blocklistEditor
+	e.pattern.SingleLine = true
+	e.list.Axis = layout.Vertical
+	if e.kind == "" {
+		e.kind = g.BlockGlob
+	}
+
+	rules := apps.Blocklist()
+	for len(e.unblock) < len(rules) {
+		e.unblock = append(e.unblock, widget.Clickable{})
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		for e.unblock[i].Clicked(gtx) {
+			if err := apps.RemoveBlockRule(i); err != nil {
+				l.message("Error unblocking: " + err.Error())
+			}
+		}
+	}
+	for e.cycleKind.Clicked(gtx) {
+		e.kind = ruleKinds[(slices.Index(ruleKinds, e.kind)+1)%len(ruleKinds)]
+	}
+	for e.add.Clicked(gtx) {
+		if err := apps.AddBlockRule(g.BlockRule{Kind: e.kind, Pattern: e.pattern.Text()}); err != nil {
+			l.message("Error adding rule: " + err.Error())
+		} else {
+			e.pattern.SetText("")
+			l.message("")
+		}
+	}
+	for e.clear.Clicked(gtx) {
+		l.controller.Dispatch(presentation.Command{Kind: presentation.CommandOpenDialog, Dialog: presentation.Dialog{Kind: presentation.DialogClearBlocklist}})
+	}
+	for e.confirm.Clicked(gtx) {
+		if _, ok := l.model.TakeDialog(presentation.DialogClearBlocklist); ok {
+			apps.UnblockAllApplications()
+			l.message("All apps have been unblocked.")
+		}
+	}
+	for e.cancel.Clicked(gtx) {
+		l.model.TakeDialog(presentation.DialogClearBlocklist)
+	}
+	for l.back.Clicked(gtx) {
+		l.model.TakeDialog(presentation.DialogClearBlocklist)
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
+	}
+
+	rules = apps.Blocklist()
+	s := l.controller.Snapshot()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Hidden apps") }),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if len(rules) == 0 {
+				return l.label(gtx, "Nothing is hidden. Press Delete on a program result to hide it.")
+			}
+			return material.List(l.theme, &e.list).Layout(gtx, len(rules), func(gtx layout.Context, i int) layout.Dimensions {
+				text := fmt.Sprintf("[%s] %s", rules[i].Kind, rules[i].Pattern)
+				if rules[i].Kind != g.BlockPath {
+					text += fmt.Sprintf(" (%d apps)", len(apps.BlockedApps(rules[i])))
+				}
+				return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.label(gtx, text) }),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.unblock[i], "Unblock") }),
+					)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) }),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return l.button(gtx, &e.cycleKind, e.kind)
+					})
+				}),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.pattern, kindHint(e.kind)) }),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.add, "Add") }),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if s.Message == "" {
+				return layout.Dimensions{}
+			}
+			return l.label(gtx, s.Message)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			}
+			if s.Dialog.Kind == presentation.DialogClearBlocklist {
+				children = append(children,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.confirm, "Confirm clear") }),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.cancel, "Cancel") }),
+				)
+			} else {
+				children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return l.button(gtx, &e.clear, fmt.Sprintf("Clear all (%d)", len(rules)))
+				}))
+			}
+			return layout.Flex{}.Layout(gtx, children...)
+		}),
+	)
+}
+
+func kindHint(kind string) string {
+	switch kind {
+	case g.BlockPath:
+		return `c:\program files\app\app.exe`
+	case g.BlockName:
+		return "Application name"
+	case g.BlockRegex:
+		return `(?:uninstall|helper)\.exe$`
+	default:
+		return `c:\program files\vendor\*\*.exe`
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	g "winfastnav/internal/globals"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/search"
+)
+
+type engineEditor struct {
+	name, keyword, url, bangPath widget.Editor
+	add, importBangs, bare       widget.Clickable
+	list                         widget.List
+	makeDefault, remove          []widget.Clickable
+}
+
+func (l *launcher) enginesPage(gtx layout.Context) layout.Dimensions {
+	e := &l.engineEditor
+	e.name.SingleLine, e.keyword.SingleLine, e.url.SingleLine, e.bangPath.SingleLine = true, true, true, true
+	e.list.Axis = layout.Vertical
+
+	engines := search.Engines()
+	for len(e.makeDefault) < len(engines) {
+		e.makeDefault = append(e.makeDefault, widget.Clickable{})
+		e.remove = append(e.remove, widget.Clickable{})
+	}
+
+	for i := range engines {
+		for e.makeDefault[i].Clicked(gtx) {
+			if err := search.SetDefault(i); err != nil {
+				l.message("Error setting default engine: " + err.Error())
+			}
+		}
+		for e.remove[i].Clicked(gtx) {
+			if err := search.RemoveEngine(i); err != nil {
+				l.message("Error removing engine: " + err.Error())
+			}
+		}
+	}
+	for e.add.Clicked(gtx) {
+		err := search.AddEngine(g.SearchEngine{Name: e.name.Text(), Keyword: e.keyword.Text(), URL: e.url.Text()})
+		if err != nil {
+			l.message("Error adding engine: " + err.Error())
+		} else {
+			e.name.SetText("")
+			e.keyword.SetText("")
+			e.url.SetText("")
+			l.message("")
+		}
+	}
+	for e.importBangs.Clicked(gtx) {
+		added, err := search.ImportBangs(e.bangPath.Text())
+		if err != nil {
+			l.message("Error importing bangs: " + err.Error())
+		} else {
+			l.message(fmt.Sprintf("Imported %d search engines.", added))
+		}
+	}
+	for e.bare.Clicked(gtx) {
+		if err := search.SetBareKeywords(!search.BareKeywords()); err != nil {
+			l.message("Error saving keyword setting: " + err.Error())
+		}
+	}
+	for l.back.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
+	}
+
+	// Re-read in case a click above changed the list
+	engines = search.Engines()
+	s := l.controller.Snapshot()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Search engines") }),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(l.theme, &e.list).Layout(gtx, len(engines), func(gtx layout.Context, i int) layout.Dimensions {
+				return l.engineRow(gtx, engines[i], &e.makeDefault[i], &e.remove[i])
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) }),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(0.3, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.name, "Name") }),
+				layout.Flexed(0.2, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.keyword, "Keyword") }),
+				layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.url, "https://...?q=%s") }),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.add, "Add") }),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.bangPath, "Path to bang.json") }),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.importBangs, "Import bangs") }),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := "Keywords need ! outside :w"
+			if search.BareKeywords() {
+				text = "Keywords work without ! everywhere"
+			}
+			return l.button(gtx, &e.bare, text)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if s.Message == "" {
+				return layout.Dimensions{}
+			}
+			return l.label(gtx, s.Message)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
+	)
+}
+
+func (l *launcher) engineRow(gtx layout.Context, engine g.SearchEngine, makeDefault, remove *widget.Clickable) layout.Dimensions {
+	text := fmt.Sprintf("%s [%s]", engine.Name, engine.Keyword)
+	if engine.Default {
+		text += " (default)"
+	}
+	return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.label(gtx, text) }),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if engine.Default {
+					return layout.Dimensions{}
+				}
+				return l.button(gtx, makeDefault, "Default")
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, remove, "Remove") }),
+		)
+	})
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+	"winfastnav/internal/presentation"
+)
+
+// transcriptPage shows the chat so far, followed by the answer being streamed or the last error.
+func (l *launcher) transcriptPage(gtx layout.Context, s presentation.State) layout.Dimensions {
+	lines := l.model.Transcript()
+	if s.Message != "" {
+		lines = append(lines, s.Message)
+	}
+
+	l.transcript.Axis = layout.Vertical
+	// stay at the bottom while an answer streams in, unless the user scrolled up
+	l.transcript.ScrollToEnd = true
+	return material.List(l.theme, &l.transcript).Layout(gtx, len(lines), func(gtx layout.Context, i int) layout.Dimensions {
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return l.label(gtx, lines[i])
+		})
+	})
+}
@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/hotkey"
+	"winfastnav/internal/presentation"
+)
+
+type hotkeyRow struct {
+	keys          widget.Editor
+	action        hotkey.Action
+	cycle, remove widget.Clickable
+}
+
+type hotkeyEditor struct {
+	rows      []*hotkeyRow
+	add, save widget.Clickable
+	list      widget.List
+}
+
+var (
+	hotkeyMu     sync.RWMutex
+	hotkeyErr    error
+	reloadHotkey func()
+)
+
+// OnHotkeysChanged sets the function called after the hotkey settings are saved.
+func OnHotkeysChanged(reload func()) {
+	hotkeyMu.Lock()
+	reloadHotkey = reload
+	hotkeyMu.Unlock()
+}
+
+// SetHotkeyError records the result of the last hotkey registration, nil if every binding worked.
+func SetHotkeyError(err error) {
+	hotkeyMu.Lock()
+	hotkeyErr = err
+	hotkeyMu.Unlock()
+	if active != nil {
+		active.window.Invalidate()
+	}
+}
+
+// hotkeyProblems describes the bindings that failed to register, one per line.
+func hotkeyProblems() string {
+	hotkeyMu.RLock()
+	err := hotkeyErr
+	hotkeyMu.RUnlock()
+	if err == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, e := range unjoin(err) {
+		var bindingErr *hotkey.BindingError
+		if errors.As(e, &bindingErr) && errors.Is(e, hotkey.ErrTaken) {
+			lines = append(lines, bindingErr.Binding.Keys+" is already used by another application.")
+		} else {
+			lines = append(lines, e.Error())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func hotkeyHelp() string {
+	var b strings.Builder
+	for _, binding := range hotkey.Bindings() {
+		keys := binding.Keys
+		if combination, err := hotkey.Parse(keys); err == nil {
+			keys = combination.String()
+		}
+		b.WriteString(keys + ": " + actionLabel(binding.Action) + "\n")
+	}
+	if problems := hotkeyProblems(); problems != "" {
+		b.WriteString(problems + "\n")
+	}
+	return b.String()
+}
+
+func actionLabel(action hotkey.Action) string {
+	switch action {
+	case hotkey.ActionShowDocuments:
+		return "Document search"
+	case hotkey.ActionShowWindows:
+		return "Switch window"
+	case hotkey.ActionShowGPT:
+		return "Quick GPT"
+	default:
+		return "Summon"
+	}
+}
+
+// openHotkeys loads the saved bindings into the editor and shows the page.
+func (l *launcher) openHotkeys() {
+	e := &l.hotkeyEditor
+	e.rows = nil
+	for _, binding := range hotkey.Bindings() {
+		e.rows = append(e.rows, newHotkeyRow(binding))
+	}
+	l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageHotkeys})
+}
+
+func newHotkeyRow(binding hotkey.Binding) *hotkeyRow {
+	row := &hotkeyRow{action: binding.Action}
+	row.keys.SingleLine = true
+	row.keys.SetText(binding.Keys)
+	return row
+}
+
+func (l *launcher) hotkeysPage(gtx layout.Context) layout.Dimensions {
+	e := &l.hotkeyEditor
+	e.list.Axis = layout.Vertical
+
+	for i := 0; i < len(e.rows); i++ {
+		row := e.rows[i]
+		for row.cycle.Clicked(gtx) {
+			next := (slices.Index(hotkey.Actions, row.action) + 1) % len(hotkey.Actions)
+			row.action = hotkey.Actions[next]
+		}
+		for row.remove.Clicked(gtx) {
+			e.rows = append(e.rows[:i], e.rows[i+1:]...)
+			i--
+		}
+	}
+	for e.add.Clicked(gtx) {
+		e.rows = append(e.rows, newHotkeyRow(hotkey.Binding{Action: hotkey.ActionShowPrograms}))
+	}
+	for e.save.Clicked(gtx) {
+		var bindings []hotkey.Binding
+		for _, row := range e.rows {
+			bindings = append(bindings, hotkey.Binding{Keys: strings.TrimSpace(row.keys.Text()), Action: row.action})
+		}
+		if err := hotkey.SetBindings(bindings); err != nil {
+			l.message(err.Error())
+		} else {
+			l.message("Hotkeys saved.")
+			hotkeyMu.RLock()
+			reload := reloadHotkey
+			hotkeyMu.RUnlock()
+			if reload != nil {
+				reload()
+			}
+		}
+	}
+	for l.back.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
+	}
+
+	s := l.controller.Snapshot()
+	rows := e.rows
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Global hotkeys") }),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return l.label(gtx, "Example: Ctrl+Shift+Space. Click the action to change it.")
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(l.theme, &e.list).Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+				row := rows[i]
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &row.keys, "Alt+O") }),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &row.cycle, actionLabel(row.action)) }),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &row.remove, "Remove") }),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := s.Message
+			if problems := hotkeyProblems(); problems != "" {
+				text = strings.TrimSpace(text + "\n" + problems)
+			}
+			if text == "" {
+				return layout.Dimensions{}
+			}
+			return l.label(gtx, text)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.add, "Add") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.save, "Save") }),
+			)
+		}),
+	)
+}
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/documents"
+	"winfastnav/internal/presentation"
+)
+
+type indexingEditor struct {
+	draft               documents.IndexConfig
+	rootPath, rootDepth widget.Editor
+	extensions, exclude widget.Editor
+	addRoot, save       widget.Clickable
+	list                widget.List
+	removeRoot          []widget.Clickable
+	categories          map[string]*widget.Clickable
+	categoryNames       []string
+}
+
+// openIndexing loads the saved configuration into the editor and shows the page.
+func (l *launcher) openIndexing() {
+	e := &l.indexingEditor
+	e.draft = documents.GetIndexConfig()
+	e.extensions.SetText(strings.Join(e.draft.Extensions, ", "))
+	e.exclude.SetText(strings.Join(e.draft.Exclude, ", "))
+	l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageIndexing})
+}
+
+func (l *launcher) indexingPage(gtx layout.Context) layout.Dimensions {
+	e := &l.indexingEditor
+	e.rootPath.SingleLine, e.rootDepth.SingleLine, e.extensions.SingleLine, e.exclude.SingleLine = true, true, true, true
+	e.list.Axis = layout.Vertical
+	if e.categories == nil {
+		e.categoryNames = documents.CategoryNames()
+		e.categories = make(map[string]*widget.Clickable, len(e.categoryNames))
+		for _, name := range e.categoryNames {
+			e.categories[name] = new(widget.Clickable)
+		}
+	}
+	for len(e.removeRoot) < len(e.draft.Roots) {
+		e.removeRoot = append(e.removeRoot, widget.Clickable{})
+	}
+
+	for i := 0; i < len(e.draft.Roots); i++ {
+		for e.removeRoot[i].Clicked(gtx) {
+			e.draft.Roots = append(e.draft.Roots[:i], e.draft.Roots[i+1:]...)
+		}
+	}
+	for e.addRoot.Clicked(gtx) {
+		maxDepth, err := strconv.Atoi(strings.TrimSpace(e.rootDepth.Text()))
+		if err != nil {
+			maxDepth = 0
+		}
+		if path := strings.TrimSpace(e.rootPath.Text()); path != "" {
+			e.draft.Roots = append(e.draft.Roots, documents.IndexRoot{Path: path, MaxDepth: maxDepth})
+			e.rootPath.SetText("")
+			e.rootDepth.SetText("")
+		}
+	}
+	for _, name := range e.categoryNames {
+		for e.categories[name].Clicked(gtx) {
+			e.draft.Categories = toggle(e.draft.Categories, name)
+		}
+	}
+	for e.save.Clicked(gtx) {
+		e.draft.Extensions = splitList(e.extensions.Text())
+		e.draft.Exclude = splitList(e.exclude.Text())
+		if err := documents.SetIndexConfig(e.draft); err != nil {
+			l.message("Error saving indexing settings: " + err.Error())
+		} else {
+			e.draft = documents.GetIndexConfig()
+			l.message("Re-indexing documents.")
+			go documents.SetupDocs()
+		}
+	}
+	for l.back.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
+	}
+
+	s := l.controller.Snapshot()
+	roots := e.draft.Roots
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Document indexing") }),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(l.theme, &e.list).Layout(gtx, len(roots), func(gtx layout.Context, i int) layout.Dimensions {
+				text := roots[i].Path
+				if roots[i].MaxDepth > 0 {
+					text += fmt.Sprintf(" (depth %d)", roots[i].MaxDepth)
+				}
+				return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.label(gtx, text) }),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.removeRoot[i], "Remove") }),
+					)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(0.8, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.rootPath, `D:\ or \\server\share`) }),
+				layout.Flexed(0.2, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &e.rootDepth, "Depth") }),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.addRoot, "Add") }),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) }),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			children := make([]layout.FlexChild, 0, len(e.categoryNames))
+			for _, name := range e.categoryNames {
+				text := name
+				if slices.Contains(e.draft.Categories, name) {
+					text = "[x] " + name
+				}
+				click := e.categories[name]
+				children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Right: unit.Dp(4), Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return l.button(gtx, click, text)
+					})
+				}))
+			}
+			return layout.Flex{}.Layout(gtx, children...)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return l.field(gtx, &e.extensions, "Extra extensions: .epub, .mobi")
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return l.field(gtx, &e.exclude, "Exclude: node_modules, *.tmp, **/build/**")
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if s.Message == "" {
+				return layout.Dimensions{}
+			}
+			return l.label(gtx, s.Message)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.save, "Save and re-index") }),
+			)
+		}),
+	)
+}
+
+// splitList splits a comma separated editor value into its trimmed entries.
+func splitList(text string) []string {
+	var out []string
+	for _, part := range strings.Split(text, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// toggle adds value to list, or removes it if it's already there.
+func toggle(list []string, value string) []string {
+	for i, item := range list {
+		if item == value {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/llm"
+	"winfastnav/internal/presentation"
+)
+
+type llmEditor struct {
+	baseURL, model, apiKey, systemPrompt widget.Editor
+	temperature, timeout                 widget.Editor
+	save, reset                          widget.Clickable
+	list                                 widget.List
+}
+
+// openLLM loads the saved provider settings into the editor and shows the page.
+func (l *launcher) openLLM() {
+	l.llmEditor.load(llm.GetConfig())
+	l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageLLM})
+}
+
+func (e *llmEditor) load(c llm.Config) {
+	e.baseURL.SetText(c.BaseURL)
+	e.model.SetText(c.Model)
+	e.apiKey.SetText(c.APIKey)
+	e.systemPrompt.SetText(c.SystemPrompt)
+	e.temperature.SetText(strconv.FormatFloat(c.Temperature, 'f', -1, 64))
+	e.timeout.SetText(strconv.Itoa(c.TimeoutSeconds))
+}
+
+func (l *launcher) llmPage(gtx layout.Context) layout.Dimensions {
+	e := &l.llmEditor
+	e.baseURL.SingleLine, e.model.SingleLine, e.apiKey.SingleLine = true, true, true
+	e.temperature.SingleLine, e.timeout.SingleLine = true, true
+	e.apiKey.Mask = '*'
+	e.list.Axis = layout.Vertical
+
+	for e.reset.Clicked(gtx) {
+		e.load(llm.DefaultConfig())
+		l.message("Defaults restored, save to keep them.")
+	}
+	for e.save.Clicked(gtx) {
+		temperature, err := strconv.ParseFloat(strings.TrimSpace(e.temperature.Text()), 64)
+		if err != nil {
+			l.message("Temperature must be a number.")
+			break
+		}
+		timeout, err := strconv.Atoi(strings.TrimSpace(e.timeout.Text()))
+		if err != nil {
+			l.message("Timeout must be a whole number of seconds.")
+			break
+		}
+		err = llm.SetConfig(llm.Config{
+			BaseURL:        e.baseURL.Text(),
+			Model:          e.model.Text(),
+			APIKey:         e.apiKey.Text(),
+			SystemPrompt:   strings.TrimSpace(e.systemPrompt.Text()),
+			Temperature:    temperature,
+			TimeoutSeconds: timeout,
+		})
+		if err != nil {
+			l.message("Error saving Quick GPT settings: " + err.Error())
+		} else {
+			l.message("Quick GPT settings saved.")
+		}
+	}
+	for l.back.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
+	}
+
+	fields := []struct {
+		name   string
+		editor *widget.Editor
+		hint   string
+	}{
+		{"Base URL", &e.baseURL, "http://localhost:11434/v1"},
+		{"Model", &e.model, "llama3.2"},
+		{"API key", &e.apiKey, "Not needed for local servers"},
+		{"System prompt", &e.systemPrompt, "You are a helpful assistant."},
+		{"Temperature", &e.temperature, "0.7"},
+		{"Timeout (seconds)", &e.timeout, "120"},
+	}
+	s := l.controller.Snapshot()
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Quick GPT") }),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return l.label(gtx, "Any OpenAI-compatible server works: Ollama, llama.cpp server, LM Studio or a hosted API.")
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(l.theme, &e.list).Layout(gtx, len(fields), func(gtx layout.Context, i int) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(0.3, func(gtx layout.Context) layout.Dimensions { return l.label(gtx, fields[i].name) }),
+					layout.Flexed(0.7, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, fields[i].editor, fields[i].hint) }),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if s.Message == "" {
+				return layout.Dimensions{}
+			}
+			return l.label(gtx, s.Message)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.reset, "Defaults") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.save, "Save") }),
+			)
+		}),
+	)
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/prompts"
+)
+
+type templateRow struct {
+	name, prompt widget.Editor
+	remove       widget.Clickable
+}
+
+type templateEditor struct {
+	rows      []*templateRow
+	add, save widget.Clickable
+	list      widget.List
+}
+
+// openTemplates loads the saved prompt templates into the editor and shows the page.
+func (l *launcher) openTemplates() {
+	e := &l.templateEditor
+	e.rows = nil
+	for _, template := range prompts.Templates() {
+		e.rows = append(e.rows, newTemplateRow(template))
+	}
+	l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageTemplates})
+}
+
+func newTemplateRow(template prompts.Template) *templateRow {
+	row := &templateRow{}
+	row.name.SingleLine = true
+	row.name.SetText(template.Name)
+	row.prompt.SetText(template.Prompt)
+	return row
+}
+
+func (l *launcher) templatesPage(gtx layout.Context) layout.Dimensions {
+	e := &l.templateEditor
+	e.list.Axis = layout.Vertical
+
+	for i := 0; i < len(e.rows); i++ {
+		for e.rows[i].remove.Clicked(gtx) {
+			e.rows = append(e.rows[:i], e.rows[i+1:]...)
+			i--
+			break
+		}
+	}
+	for e.add.Clicked(gtx) {
+		e.rows = append(e.rows, newTemplateRow(prompts.Template{Prompt: "{text}"}))
+	}
+	for e.save.Clicked(gtx) {
+		var list []prompts.Template
+		for _, row := range e.rows {
+			list = append(list, prompts.Template{Name: row.name.Text(), Prompt: row.prompt.Text()})
+		}
+		if err := prompts.SetTemplates(list); err != nil {
+			l.message(err.Error())
+		} else {
+			l.message("Prompt templates saved.")
+		}
+	}
+	for l.back.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
+	}
+
+	s := l.controller.Snapshot()
+	rows := e.rows
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Prompt templates") }),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return l.label(gtx, "Use as :g name text. Placeholders: {text}, {clipboard}, {document}, {window}.")
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(l.theme, &e.list).Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+				row := rows[i]
+				return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Alignment: layout.Start}.Layout(gtx,
+						layout.Flexed(0.2, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &row.name, "name") }),
+						layout.Flexed(0.8, func(gtx layout.Context) layout.Dimensions { return l.field(gtx, &row.prompt, "Prompt with {text}") }),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &row.remove, "Remove") }),
+					)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if s.Message == "" {
+				return layout.Dimensions{}
+			}
+			return l.label(gtx, strings.TrimSpace(s.Message))
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.add, "Add") }),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &e.save, "Save") }),
+			)
+		}),
+	)
+}
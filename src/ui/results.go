@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"image"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/theme"
+)
+
+// size of the icon slot at the start of result rows
+const iconSize = unit.Dp(20)
+
+// kindBadges are shown at the right of each result.
+var kindBadges = [...]string{
+	presentation.ResultApp:      "App",
+	presentation.ResultDocument: "Document",
+	presentation.ResultWindow:   "Window",
+	presentation.ResultChat:     "Chat",
+}
+
+// span is a run of a result title drawn in one style.
+type span struct {
+	text    string
+	matched bool
+	dim     bool
+}
+
+// titleSpans splits title into runs of matched and unmatched runes. Spaces move to the start of
+// the following run, since a label drops the width of its trailing spaces.
+func titleSpans(prefix []span, title string, matches []int) []span {
+	spans := prefix
+	matched := make(map[int]bool, len(matches))
+	for _, i := range matches {
+		matched[i] = true
+	}
+	for i, r := range []rune(title) {
+		if n := len(spans); n > len(prefix) && spans[n-1].matched == matched[i] {
+			spans[n-1].text += string(r)
+			continue
+		}
+		spans = append(spans, span{text: string(r), matched: matched[i]})
+	}
+	for i := 0; i < len(spans)-1; i++ {
+		trimmed := strings.TrimRightFunc(spans[i].text, unicode.IsSpace)
+		spans[i+1].text = spans[i].text[len(trimmed):] + spans[i+1].text
+		spans[i].text = trimmed
+	}
+	return spans
+}
+
+// rowColors are the colors of a result row, the selected one stands out by its background.
+type rowColors struct {
+	background, text, match, dim theme.Color
+}
+
+func (l *launcher) rowColors(selected bool) rowColors {
+	if !selected {
+		p := l.style.Palette
+		return rowColors{background: p.Background, text: p.Text, match: p.Match, dim: p.Hint}
+	}
+	text := l.style.Selected.Text
+	dim := text
+	dim.A = text.A / 4 * 3
+	return rowColors{background: l.style.Selected.Background, text: text, match: text, dim: dim}
+}
+
+// resultRow draws a result: the icon slot when iconSlot is set, the title with the matched
+// characters highlighted, the detail dimmed below it and a badge with the kind on the right.
+func (l *launcher) resultRow(gtx layout.Context, click *widget.Clickable, r presentation.Result, icon *paint.ImageOp, iconSlot, selected bool) layout.Dimensions {
+	colors := l.rowColors(selected)
+	var prefix []span
+	if selected && l.style.Selected.Prefix != "" {
+		prefix = append(prefix, span{text: l.style.Selected.Prefix})
+	}
+	if r.Kind == presentation.ResultWindow {
+		prefix = append(prefix, span{text: strconv.Itoa(r.Number) + " ", dim: true})
+	}
+	spans := titleSpans(prefix, r.Title, r.Matches)
+
+	content := func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !iconSlot {
+					return layout.Dimensions{}
+				}
+				size := gtx.Dp(iconSize)
+				gtx.Constraints = layout.Exact(image.Pt(size, size))
+				if icon == nil {
+					return layout.Dimensions{Size: gtx.Constraints.Max}
+				}
+				return widget.Image{Src: *icon, Fit: widget.Contain}.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !iconSlot {
+					return layout.Dimensions{}
+				}
+				return layout.Spacer{Width: unit.Dp(6)}.Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.spans(gtx, spans, colors) }),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if r.Detail == "" {
+							return layout.Dimensions{}
+						}
+						return l.rowText(gtx, r.Detail, 0.7, colors.dim, font.Normal)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return l.rowText(gtx, kindBadges[r.Kind], 0.7, colors.dim, font.Normal)
+			}),
+		)
+	}
+
+	return layout.Inset{Bottom: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return click.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Background{}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				rect := image.Rectangle{Max: gtx.Constraints.Min}
+				paint.FillShape(gtx.Ops, colors.background.NRGBA(), clip.UniformRRect(rect, gtx.Dp(unit.Dp(l.style.CornerRadius))).Op(gtx.Ops))
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			}, func(gtx layout.Context) layout.Dimensions {
+				inset := unit.Dp(l.style.ButtonInset)
+				return layout.Inset{Top: inset, Bottom: inset, Left: inset + 2, Right: inset + 2}.Layout(gtx, content)
+			})
+		})
+	})
+}
+
+// spans draws runs of text one after the other on a line, the last visible one truncated.
+func (l *launcher) spans(gtx layout.Context, spans []span, colors rowColors) layout.Dimensions {
+	x, height := 0, 0
+	for _, s := range spans {
+		if s.text == "" {
+			continue
+		}
+		if x >= gtx.Constraints.Max.X {
+			break
+		}
+		color, weight := colors.text, font.Normal
+		if s.matched {
+			color, weight = colors.match, font.Bold
+		} else if s.dim {
+			color = colors.dim
+		}
+		cgtx := gtx
+		cgtx.Constraints = layout.Constraints{Max: image.Pt(gtx.Constraints.Max.X-x, gtx.Constraints.Max.Y)}
+		offset := op.Offset(image.Pt(x, 0)).Push(gtx.Ops)
+		dims := l.rowText(cgtx, s.text, 0.85, color, weight)
+		offset.Pop()
+		x += dims.Size.X
+		height = max(height, dims.Size.Y)
+	}
+	return layout.Dimensions{Size: image.Pt(x, height)}
+}
+
+func (l *launcher) rowText(gtx layout.Context, text string, scale float32, color theme.Color, weight font.Weight) layout.Dimensions {
+	label := material.Label(l.theme, l.textSize(scale), text)
+	label.Color = color.NRGBA()
+	label.Font.Weight = weight
+	label.MaxLines = 1
+	return label.Layout(gtx)
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"image"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"winfastnav/internal/theme"
+)
+
+// applyTheme switches the launcher to t. Must run on the UI goroutine.
+func (l *launcher) applyTheme(t theme.Theme) {
+	l.style = t
+	l.theme.TextSize = unit.Sp(t.TextSize)
+	l.theme.Face = font.Typeface(t.FontFamily)
+	l.theme.Palette = material.Palette{
+		Bg:         t.Palette.Background.NRGBA(),
+		Fg:         t.Palette.Text.NRGBA(),
+		ContrastBg: t.Palette.Button.NRGBA(),
+		ContrastFg: t.Palette.ButtonText.NRGBA(),
+	}
+}
+
+// textSize scales the theme's base text size, so every element grows together.
+func (l *launcher) textSize(scale float32) unit.Sp {
+	return unit.Sp(l.style.TextSize * scale)
+}
+
+func (l *launcher) editorStyle(editor *widget.Editor, hint string, scale float32) material.EditorStyle {
+	style := material.Editor(l.theme, editor, hint)
+	style.TextSize = l.textSize(scale)
+	style.Color = l.style.Palette.Text.NRGBA()
+	style.HintColor = l.style.Palette.Hint.NRGBA()
+	return style
+}
+
+func (l *launcher) button(gtx layout.Context, c *widget.Clickable, text string) layout.Dimensions {
+	return l.styledButton(gtx, c, text, l.style.Palette.Button, l.style.Palette.ButtonText)
+}
+
+func (l *launcher) styledButton(gtx layout.Context, c *widget.Clickable, text string, background, foreground theme.Color) layout.Dimensions {
+	b := material.Button(l.theme, c, text)
+	b.Background = background.NRGBA()
+	b.Color = foreground.NRGBA()
+	b.CornerRadius = unit.Dp(l.style.CornerRadius)
+	b.TextSize = l.textSize(0.85)
+	inset := unit.Dp(l.style.ButtonInset)
+	b.Inset = layout.Inset{Top: inset, Bottom: inset, Left: inset + 2, Right: inset + 2}
+	return b.Layout(gtx)
+}
+
+func (l *launcher) menuButton(gtx layout.Context, c *widget.Clickable, text string) layout.Dimensions {
+	return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return l.button(gtx, c, text)
+	})
+}
+
+func (l *launcher) input(gtx layout.Context, content layout.Widget) layout.Dimensions {
+	return layout.Background{}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		rect := image.Rectangle{Max: gtx.Constraints.Min}
+		paint.FillShape(gtx.Ops, l.style.Palette.Input.NRGBA(), clip.UniformRRect(rect, gtx.Dp(unit.Dp(l.style.CornerRadius))).Op(gtx.Ops))
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}, func(gtx layout.Context) layout.Dimensions {
+		inset := unit.Dp(l.style.InputInset)
+		return layout.Inset{Top: inset, Bottom: inset, Left: inset + 1, Right: inset + 1}.Layout(gtx, content)
+	})
+}
+
+// field draws a single editor with the launcher input style.
+func (l *launcher) field(gtx layout.Context, editor *widget.Editor, hint string) layout.Dimensions {
+	return layout.Inset{Right: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return l.input(gtx, l.editorStyle(editor, hint, 0.9).Layout)
+	})
+}
+
+func (l *launcher) section(gtx layout.Context, text string) layout.Dimensions {
+	style := material.Body1(l.theme, text)
+	style.Color = l.style.Palette.Section.NRGBA()
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(3)}.Layout(gtx, style.Layout)
+}
+
+func (l *launcher) separator(gtx layout.Context) layout.Dimensions {
+	size := image.Pt(gtx.Constraints.Max.X, gtx.Dp(unit.Dp(1)))
+	paint.FillShape(gtx.Ops, l.style.Palette.Separator.NRGBA(), clip.Rect{Max: size}.Op())
+	return layout.Dimensions{Size: size}
+}
+
+func (l *launcher) label(gtx layout.Context, text string) layout.Dimensions {
+	s := material.Body1(l.theme, text)
+	s.Color = l.style.Palette.Text.NRGBA()
+	return s.Layout(gtx)
+}
+
+func (l *launcher) heading(gtx layout.Context, text string) layout.Dimensions {
+	s := material.H6(l.theme, text)
+	s.Color = l.style.Palette.Text.NRGBA()
+	return s.Layout(gtx)
+}
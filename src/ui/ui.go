@@ -1,17 +1,16 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
-	"image"
-	"image/color"
+	"io"
 	"log"
-	"net/url"
 	"os"
-	"strconv"
-	"strings"
-	"sync"
+	"slices"
+	"sync/atomic"
 
 	"gioui.org/app"
+	"gioui.org/io/event"
 	"gioui.org/io/key"
 	"gioui.org/io/system"
 	"gioui.org/layout"
@@ -23,43 +22,69 @@ import (
 	"gioui.org/widget/material"
 	"github.com/getlantern/systray"
 	"winfastnav/internal/apps"
-	"winfastnav/internal/core"
+	"winfastnav/internal/autostart"
 	"winfastnav/internal/documents"
+	"winfastnav/internal/gioshell"
 	g "winfastnav/internal/globals"
+	"winfastnav/internal/hotkey"
+	model "winfastnav/internal/launcher"
+	"winfastnav/internal/llm"
 	"winfastnav/internal/presentation"
-	"winfastnav/internal/utils"
+	"winfastnav/internal/prompts"
+	"winfastnav/internal/search"
+	"winfastnav/internal/theme"
 	"winfastnav/internal/windowcontrol"
 )
 
-const maxResults = 30
-
 type launcher struct {
 	controller                                    *presentation.Controller
 	windowControl                                 *windowcontrol.Controller
 	window                                        app.Window
 	ops                                           op.Ops
 	theme                                         *material.Theme
-	editor, settings                              widget.Editor
-	list                                          widget.List
-	results                                       [maxResults]widget.Clickable
+	editor                                        widget.Editor
+	list, settingsList                            widget.List
+	results                                       [model.MaxResults]widget.Clickable
 	menu, back, help, settingsButton, about, quit widget.Clickable
-	startup, clear, confirm, cancel               widget.Clickable
-	mu                                            sync.RWMutex
-	items                                         []g.Resource
-	windows                                       []string
-	confirmClear                                  bool
-	centered                                      bool
+	startup, blocklist, undo, engines, themeName  widget.Clickable
+	indexing, hotkeys, llmButton, templates       widget.Clickable
+	engineEditor                                  engineEditor
+	blocklistEditor                               blocklistEditor
+	indexingEditor                                indexingEditor
+	hotkeyEditor                                  hotkeyEditor
+	llmEditor                                     llmEditor
+	templateEditor                                templateEditor
+	model                                         *model.Launcher
+	// window icons uploaded once, so frames only reference them
+	windowIcons  map[uint64]paint.ImageOp
+	centered     bool
+	style        theme.Theme
+	refreshTheme atomic.Bool
+	transcript   widget.List
+	// page drawn by the last frame, to notice a page being opened
+	shownPage presentation.Page
+	// start at login status, read when the settings page opens
+	startupState autostart.State
+	startupErr   error
 }
 
-var active *launcher
+var (
+	active *launcher
+	// inspecting is set while the inspector window is open
+	inspecting atomic.Bool
+)
 
 func SetupUI() {
-	theme := material.NewTheme()
-	theme.TextSize = unit.Sp(12.35)
-	active = &launcher{controller: presentation.NewController(g.ModeSearchProgram), theme: theme, list: widget.List{List: layout.List{Axis: layout.Vertical}}}
+	active = &launcher{controller: presentation.NewController(g.ModeSearchProgram), theme: material.NewTheme(), list: widget.List{List: layout.List{Axis: layout.Vertical}}, settingsList: widget.List{List: layout.List{Axis: layout.Vertical}}}
 	active.editor.SingleLine, active.editor.Submit = true, true
+	active.applyTheme(theme.Current())
 	active.window.Option(app.Title(g.AppName), app.Size(unit.Dp(425), unit.Dp(300)), app.MinSize(unit.Dp(425), unit.Dp(300)), app.MaxSize(unit.Dp(425), unit.Dp(300)), app.Decorated(false), app.TopMost(true))
 	active.windowControl = windowcontrol.New(g.AppName)
+	active.model = model.New(active.controller, model.System(func() {
+		go func() {
+			_ = active.windowControl.Hide()
+		}()
+	}, Quit))
 	active.controller.Post(presentation.Command{Kind: presentation.CommandShow})
 	active.message(g.AppName + "\nMenu -> Help")
 }
@@ -76,17 +101,26 @@ func Run() {
 }
 
 func ShowWindow() {
+	ShowWindowMode(g.ModeSearchProgram)
+}
+
+// ShowWindowMode shows the launcher with an empty query in the given mode.
+func ShowWindowMode(mode int) {
 	if active == nil {
 		return
 	}
-	g.CurrentMode = g.ModeSearchProgram
-	active.clearItems()
-	active.controller.Post(presentation.Command{Kind: presentation.CommandShow})
-	active.controller.Post(presentation.Command{Kind: presentation.CommandSetMode, Mode: g.ModeSearchProgram})
-	active.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageLauncher})
-	active.controller.Post(presentation.Command{Kind: presentation.CommandSetQuery})
-	active.controller.Post(presentation.Command{Kind: presentation.CommandSetResults})
-	active.message(g.AppName + "\nMenu -> Help")
+	active.refreshTheme.Store(true)
+	active.model.Show(mode)
+	_ = active.windowControl.ShowAndFocus()
+	active.window.Invalidate()
+}
+
+// SetQuery shows the launcher in its current mode and types text into the search box.
+func SetQuery(text string) {
+	if active == nil {
+		return
+	}
+	active.model.ShowQuery(text)
 	_ = active.windowControl.ShowAndFocus()
 	active.window.Invalidate()
 }
@@ -95,15 +129,44 @@ func HideWindow() {
 	if active == nil {
 		return
 	}
-	active.clearItems()
-	active.controller.Post(presentation.Command{Kind: presentation.CommandSetQuery})
-	active.controller.Post(presentation.Command{Kind: presentation.CommandSetResults})
-	active.controller.Post(presentation.Command{Kind: presentation.CommandHide})
+	active.model.Hide()
+}
+
+// Visible reports whether the launcher window is shown.
+func Visible() bool {
+	return active != nil && active.controller.Snapshot().Visible
+}
+
+// Subscribe calls observer with every change to the launcher's state until unsubscribe is
+// called. Observers must return quickly, see presentation.Controller.Subscribe.
+func Subscribe(observer func(presentation.Change)) (unsubscribe func()) {
+	if active == nil {
+		return func() {}
+	}
+	return active.controller.Subscribe(observer)
+}
+
+// Inspect opens the developer inspector on the launcher's state, unless it's already open.
+func Inspect() {
+	if active == nil || !inspecting.CompareAndSwap(false, true) {
+		return
+	}
 	go func() {
-		_ = active.windowControl.Hide()
+		defer inspecting.Store(false)
+		if err := gioshell.New(active.controller, g.AppName+" inspector").Run(); err != nil {
+			log.Printf("inspector closed: %v", err)
+		}
 	}()
 }
 
+// WriteHistory saves the launcher's recent commands, see presentation.Controller.WriteHistory.
+func WriteHistory(w io.Writer) error {
+	if active == nil {
+		return errors.New("the launcher isn't set up")
+	}
+	return active.controller.WriteHistory(w)
+}
+
 func ShowAbout() {
 	if active != nil {
 		active.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageAbout})
@@ -140,17 +203,33 @@ func (l *launcher) run() error {
 }
 
 func (l *launcher) update(gtx layout.Context) {
+	if l.refreshTheme.Swap(false) {
+		l.applyTheme(theme.Current())
+	}
 	state := l.controller.Snapshot()
 	if l.editor.Text() != state.Query {
 		l.editor.SetText(state.Query)
 	}
+	filters := append([]event.Filter{key.Filter{Name: key.NameUpArrow}, key.Filter{Name: key.NameDownArrow}, key.Filter{Name: key.NameReturn}, key.Filter{Name: key.NameEnter}, key.Filter{Name: key.NameEscape}, key.Filter{Name: key.NameDeleteForward}}, windowShortcutFilters()...)
 	for {
-		e, ok := gtx.Source.Event(key.Filter{Name: key.NameUpArrow}, key.Filter{Name: key.NameDownArrow}, key.Filter{Name: key.NameReturn}, key.Filter{Name: key.NameEnter}, key.Filter{Name: key.NameEscape}, key.Filter{Name: key.NameDeleteForward})
+		e, ok := gtx.Source.Event(filters...)
 		if !ok {
 			break
 		}
-		if k, ok := e.(key.Event); ok && k.State == key.Press {
-			l.key(k.Name)
+		k, ok := e.(key.Event)
+		if !ok || k.State != key.Press {
+			continue
+		}
+		if k.Modifiers.Contain(key.ModAlt) {
+			if action, ok := windowShortcuts[k.Name]; ok {
+				l.model.WindowAction(action)
+			}
+			continue
+		}
+		if k.Name == key.NameEscape && l.controller.Snapshot().Page != presentation.PageLauncher {
+			l.launcher()
+		} else if modelKey, ok := keys[k.Name]; ok {
+			l.model.Press(modelKey)
 		}
 	}
 	for {
@@ -160,209 +239,58 @@ func (l *launcher) update(gtx layout.Context) {
 		}
 		switch e.(type) {
 		case widget.ChangeEvent:
-			l.query(l.editor.Text())
+			l.model.Type(l.editor.Text())
 		case widget.SubmitEvent:
-			l.submit(l.editor.Text())
-		}
-	}
-}
-
-func (l *launcher) key(name key.Name) {
-	s := l.controller.Snapshot()
-	switch name {
-	case key.NameEscape:
-		if s.Page == presentation.PageLauncher {
-			HideWindow()
-		} else {
-			l.launcher()
+			l.model.Submit(l.editor.Text())
 		}
-	case key.NameUpArrow:
-		l.selectResult(s.Selected - 1)
-	case key.NameDownArrow:
-		l.selectResult(s.Selected + 1)
-	case key.NameReturn, key.NameEnter:
-		if s.Selected >= 0 {
-			l.open(s.Selected)
-		}
-	case key.NameDeleteForward:
-		if g.CurrentMode == g.ModeSearchProgram && s.Selected >= 0 {
-			l.block(s.Selected)
-		}
-	}
-}
-
-func (l *launcher) query(query string) {
-	l.controller.Post(presentation.Command{Kind: presentation.CommandSetQuery, Query: query})
-	if g.CurrentMode == g.ModeChooseProgram {
-		return
-	}
-	items, message := core.HandleTextInput(query)
-	if message != nil {
-		l.clearItems()
-		l.controller.Post(presentation.Command{Kind: presentation.CommandSetResults})
-		l.message(*message)
-		return
-	}
-	if g.CurrentMode == g.ModeSearchProgram || g.CurrentMode == g.ModeSearchDocument {
-		l.mu.Lock()
-		l.items, l.windows = items, nil
-		l.mu.Unlock()
-		l.controller.Post(presentation.Command{Kind: presentation.CommandSetResults, ResultCount: len(items)})
-		l.message("")
 	}
 }
 
-func (l *launcher) submit(input string) {
-	if input == "" {
-		return
-	}
-	if strings.HasPrefix(input, ":") {
-		l.editor.SetText("")
-		if len(input) == 1 {
-			l.message("Enter a command. Menu -> Help lists the available commands.")
-			return
-		}
-		switch input[1] {
-		case 'p':
-			l.mode(g.ModeSearchProgram)
-		case 'd':
-			l.mode(g.ModeSearchDocument)
-		case 'w':
-			l.mode(g.ModeSearchInternet)
-		case 's':
-			l.mode(g.ModeChooseProgram)
-		case 'g':
-			l.mode(g.ModeAskGPT)
-		case 'r':
-			l.message("Re-indexing programs and documents.")
-			go documents.SetupDocs()
-			go apps.SetupApps()
-		case 'q':
-			HideWindow()
-		case 'x':
-			Quit()
-		}
-		return
-	}
-	if strings.HasPrefix(input, "=") {
-		expr := strings.ReplaceAll(strings.TrimPrefix(input, "="), " ", "")
-		if utils.IsMath(expr) {
-			if result, err := utils.EvalMath(expr); err == nil {
-				l.editor.SetText(result)
-				l.query(result)
-				return
-			}
-		}
-	}
-	switch g.CurrentMode {
-	case g.ModeAskGPT:
-		l.controller.Post(presentation.Command{Kind: presentation.CommandSetLoading, Loading: true})
-		l.message("Please wait...")
-		go func(p string) {
-			result := utils.MakeGPTReq(p)
-			l.controller.Post(presentation.Command{Kind: presentation.CommandSetLoading, Loading: false})
-			l.message(result)
-		}(input)
-	case g.ModeChooseProgram:
-		if n, err := strconv.Atoi(input); err == nil {
-			apps.FocusWindow(n)
-			HideWindow()
-		}
-	case g.ModeSearchInternet:
-		if err := utils.OpenURI(strings.ReplaceAll(g.SearchString, "%s", url.QueryEscape(input))); err != nil {
-			l.message("Sorry, there was an error opening your web browser.")
-		} else {
-			HideWindow()
-		}
-	default:
-		if s := l.controller.Snapshot(); s.Selected >= 0 {
-			l.open(s.Selected)
-		}
-	}
+// keys maps the keys handled outside the editor to the launcher's.
+var keys = map[key.Name]model.Key{
+	key.NameUpArrow:       model.KeyUp,
+	key.NameDownArrow:     model.KeyDown,
+	key.NameReturn:        model.KeyEnter,
+	key.NameEnter:         model.KeyEnter,
+	key.NameEscape:        model.KeyEscape,
+	key.NameDeleteForward: model.KeyDelete,
 }
 
-func (l *launcher) mode(mode int) {
-	g.CurrentMode = mode
-	l.clearItems()
-	l.controller.Post(presentation.Command{Kind: presentation.CommandSetMode, Mode: mode})
-	l.controller.Post(presentation.Command{Kind: presentation.CommandSetResults})
-	l.message("")
-	if mode == g.ModeChooseProgram {
-		windows := apps.GetOpenWindows()
-		l.mu.Lock()
-		l.windows = windows
-		l.mu.Unlock()
-		l.controller.Post(presentation.Command{Kind: presentation.CommandSetResults, ResultCount: len(windows)})
-	}
-}
-func (l *launcher) selectResult(index int) {
-	s := l.controller.Snapshot()
-	if s.ResultCount == 0 {
-		return
-	}
-	if index < 0 {
-		index = 0
-	}
-	if index >= s.ResultCount {
-		index = s.ResultCount - 1
-	}
-	l.controller.Post(presentation.Command{Kind: presentation.CommandSelectResult, Selected: index})
-}
-func (l *launcher) open(index int) {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-	if g.CurrentMode == g.ModeChooseProgram && index < len(l.windows) {
-		apps.FocusWindow(index + 1)
-		HideWindow()
-		return
-	}
-	if index >= len(l.items) {
-		return
-	}
-	item := l.items[index]
-	var err error
-	if g.CurrentMode == g.ModeSearchProgram {
-		err = apps.OpenProgram(item.Filepath)
-	} else if g.CurrentMode == g.ModeSearchDocument {
-		err = documents.OpenFile(item.Filepath)
-	}
-	if err != nil {
-		l.message("Sorry, there was an error opening the selected item.")
-		return
-	}
-	HideWindow()
-}
-func (l *launcher) block(index int) {
-	l.mu.RLock()
-	if index >= len(l.items) {
-		l.mu.RUnlock()
-		return
-	}
-	item := l.items[index]
-	l.mu.RUnlock()
-	apps.BlockApplication(item)
-	l.query(l.editor.Text())
-}
-func (l *launcher) clearItems() { l.mu.Lock(); l.items, l.windows = nil, nil; l.mu.Unlock() }
-func (l *launcher) message(text string) {
-	l.controller.Post(presentation.Command{Kind: presentation.CommandSetMessage, Message: utils.WrapTextByWords(text, 64)})
-}
+func (l *launcher) message(text string) { l.model.Message(text) }
 func (l *launcher) launcher() {
-	l.confirmClear = false
+	l.model.TakeDialog(presentation.DialogClearBlocklist)
 	l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageLauncher})
 }
 
 func (l *launcher) layout(gtx layout.Context) layout.Dimensions {
-	paint.FillShape(gtx.Ops, color.NRGBA{R: 0x1a, G: 0x18, B: 0x18, A: 0xff}, clip.Rect{Max: gtx.Constraints.Max}.Op())
+	paint.FillShape(gtx.Ops, l.style.Palette.Background.NRGBA(), clip.Rect{Max: gtx.Constraints.Max}.Op())
 	s := l.controller.Snapshot()
-	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+	if s.Page != l.shownPage {
+		l.shownPage = s.Page
+		if s.Page == presentation.PageSettings {
+			l.startupState, l.startupErr = autostart.Status()
+		}
+	}
+	return layout.UniformInset(unit.Dp(l.style.WindowInset)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		switch s.Page {
 		case presentation.PageMenu:
 			return l.menuPage(gtx)
 		case presentation.PageHelp:
-			return l.textPage(gtx, "Help", "ALT + O: Summon\nESC: Hide\nDelete: Hide app\n\n:p Program search\n:d Document search\n:w Internet search\n:s Switch window\n:g Quick GPT\n:r Re-index\n:x Quit\n\nUse = for calculations and conversions.")
+			return l.textPage(gtx, "Help", hotkeyHelp()+"ESC: Hide\nDelete: Hide app\n\n:p Program search\n:d Document search\n:w Internet search\ngh text or !gh text: Search with keyword\n:s Switch window, type to filter\n    Alt+Left/Right: Snap, Alt+Up: Maximize, Alt+Down: Minimize\n    Alt+M: Next monitor, Alt+T: On top, Alt+W: Close, Alt+K: Kill\n:g Quick GPT\n:g tr text: Prompt template\n:n New chat\n:c Previous chats\n:a Tell it what to do\n:ws name: Restore workspace, :ws save name [+docs]\n:r Re-index\n:x Quit\n\nUse = for calculations and conversions.")
 		case presentation.PageSettings:
 			return l.settingsPage(gtx)
+		case presentation.PageEngines:
+			return l.enginesPage(gtx)
+		case presentation.PageIndexing:
+			return l.indexingPage(gtx)
+		case presentation.PageHotkeys:
+			return l.hotkeysPage(gtx)
+		case presentation.PageLLM:
+			return l.llmPage(gtx)
+		case presentation.PageTemplates:
+			return l.templatesPage(gtx)
+		case presentation.PageBlocklist:
+			return l.blocklistPage(gtx)
 		case presentation.PageAbout:
 			return l.textPage(gtx, "winfastnav", "Fast Windows navigation\n\nmarkski.ar\ngithub.com/markski1")
 		default:
@@ -375,16 +303,22 @@ func (l *launcher) launcherPage(gtx layout.Context, s presentation.State) layout
 	for l.menu.Clicked(gtx) {
 		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageMenu})
 	}
+	for l.undo.Clicked(gtx) {
+		l.model.UndoBlock()
+	}
+	canUndo := l.model.CanUndo()
 	hint := placeholder(s.Mode)
-	if s.Mode == g.ModeSearchDocument && !g.FinishedCachingDocs {
+	if s.Mode == g.ModeSearchDocument && !documents.Ready() {
 		hint = "Document search [still caching]..."
 	}
-	editor := material.Editor(l.theme, &l.editor, hint)
-	editor.TextSize = unit.Sp(13)
-	editor.Color = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-	editor.HintColor = color.NRGBA{R: 180, G: 180, B: 180, A: 255}
+	editor := l.editorStyle(&l.editor, hint, 1.05)
 	dimensions := layout.Flex{Axis: layout.Vertical}.Layout(gtx, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.input(gtx, editor.Layout) }), layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.menu, "Menu") }))
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.input(gtx, editor.Layout) }), layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !canUndo {
+				return layout.Dimensions{}
+			}
+			return l.button(gtx, &l.undo, "Undo")
+		}), layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.menu, "Menu") }))
 	}), layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout), layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.resultsPage(gtx, s) }))
 	if s.FocusSearch {
 		gtx.Source.Execute(key.FocusCmd{Tag: &l.editor})
@@ -394,31 +328,33 @@ func (l *launcher) launcherPage(gtx layout.Context, s presentation.State) layout
 }
 
 func (l *launcher) resultsPage(gtx layout.Context, s presentation.State) layout.Dimensions {
-	l.mu.RLock()
-	var labels []string
-	if g.CurrentMode == g.ModeChooseProgram {
-		labels = append(labels, l.windows...)
-	} else {
-		for _, item := range l.items {
-			labels = append(labels, item.Name)
-		}
+	if s.Mode == g.ModeAskGPT && len(s.Results) == 0 {
+		return l.transcriptPage(gtx, s)
+	}
+	if s.Mode != g.ModeChooseProgram {
+		l.windowIcons = nil
 	}
-	l.mu.RUnlock()
-	if len(labels) == 0 {
+	if len(s.Results) == 0 {
 		if s.Message == "" {
 			return layout.Dimensions{}
 		}
 		return l.label(gtx, s.Message)
 	}
-	return material.List(l.theme, &l.list).Layout(gtx, len(labels), func(gtx layout.Context, index int) layout.Dimensions {
-		for l.results[index].Clicked(gtx) {
-			l.open(index)
+	icons := make([]*paint.ImageOp, len(s.Results))
+	iconSlot := false
+	for i, r := range s.Results {
+		if r.Kind == presentation.ResultWindow {
+			icons[i] = l.windowIcon(r)
+			iconSlot = true
 		}
-		text := labels[index]
-		if index == s.Selected {
-			text = "> " + text
+	}
+	// the switcher can list more windows than there are rows
+	count := min(len(s.Results), len(l.results))
+	return material.List(l.theme, &l.list).Layout(gtx, count, func(gtx layout.Context, index int) layout.Dimensions {
+		for l.results[index].Clicked(gtx) {
+			l.model.Open(index)
 		}
-		return l.button(gtx, &l.results[index], text)
+		return l.resultRow(gtx, &l.results[index], s.Results[index], icons[index], iconSlot, index == s.Selected)
 	})
 }
 
@@ -427,7 +363,6 @@ func (l *launcher) menuPage(gtx layout.Context) layout.Dimensions {
 		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageHelp})
 	}
 	for l.settingsButton.Clicked(gtx) {
-		l.settings.SetText(g.SearchString)
 		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageSettings})
 	}
 	for l.about.Clicked(gtx) {
@@ -449,112 +384,103 @@ func (l *launcher) textPage(gtx layout.Context, title, text string) layout.Dimen
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, title) }), layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return l.label(gtx, text) }), layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }))
 }
 func (l *launcher) settingsPage(gtx layout.Context) layout.Dimensions {
-	for {
-		e, ok := l.settings.Update(gtx)
-		if !ok {
-			break
-		}
-		if _, changed := e.(widget.ChangeEvent); changed {
-			core.UpdateSearchSetting(l.settings.Text())
-		}
+	for l.engines.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageEngines})
+	}
+	for l.hotkeys.Clicked(gtx) {
+		l.openHotkeys()
+	}
+	for l.indexing.Clicked(gtx) {
+		l.openIndexing()
+	}
+	for l.llmButton.Clicked(gtx) {
+		l.openLLM()
+	}
+	for l.templates.Clicked(gtx) {
+		l.openTemplates()
 	}
 	for l.startup.Clicked(gtx) {
-		if err := utils.AddToStartup(); err != nil {
-			l.message("Error adding to startup: " + err.Error())
+		var err error
+		if l.startupState.Enabled {
+			err = autostart.Disable()
 		} else {
-			l.message("winfastnav added to startup!")
+			err = autostart.Enable()
+		}
+		if err != nil {
+			l.message("Error changing start at login: " + err.Error())
+		} else {
+			l.startupState, l.startupErr = autostart.Status()
 		}
 	}
-	for l.clear.Clicked(gtx) {
-		l.confirmClear = true
-	}
-	for l.confirm.Clicked(gtx) {
-		apps.UnblockAllApplications()
-		l.confirmClear = false
-		l.message("All apps have been unblocked.")
+	startupLabel := "Start at login: Off"
+	switch {
+	case l.startupErr != nil:
+		startupLabel = "Start at login: unknown (" + l.startupErr.Error() + ")"
+	case l.startupState.Enabled:
+		startupLabel = "Start at login: On"
+	}
+	for l.themeName.Clicked(gtx) {
+		names := theme.Names()
+		next := names[(slices.Index(names, theme.Selected())+1)%len(names)]
+		if err := theme.Select(next); err != nil {
+			l.message("Error saving theme: " + err.Error())
+		}
+		l.applyTheme(theme.Current())
 	}
-	for l.cancel.Clicked(gtx) {
-		l.confirmClear = false
+	for l.blocklist.Clicked(gtx) {
+		l.controller.Post(presentation.Command{Kind: presentation.CommandSetPage, Page: presentation.PageBlocklist})
 	}
 	for l.back.Clicked(gtx) {
 		l.launcher()
 	}
-	editor := material.Editor(l.theme, &l.settings, "https://duckduckgo.com/?q=%s")
-	editor.TextSize = unit.Sp(13)
-	editor.Color = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-	editor.HintColor = color.NRGBA{R: 180, G: 180, B: 180, A: 255}
+	sections := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "SEARCH") },
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.engines, fmt.Sprintf("Search engines (%d)", len(search.Engines())))
+		},
+		func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) },
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "APPEARANCE") },
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.themeName, "Theme: "+theme.Selected())
+		},
+		func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) },
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "HOTKEYS") },
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.hotkeys, fmt.Sprintf("Global hotkeys (%d)", len(hotkey.Bindings())))
+		},
+		func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) },
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "DOCUMENTS") },
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.indexing, fmt.Sprintf("Indexed folders (%d)", len(documents.GetIndexConfig().Roots)))
+		},
+		func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) },
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "QUICK GPT") },
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.llmButton, "Model: "+llm.GetConfig().Model)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.templates, fmt.Sprintf("Prompt templates (%d)", len(prompts.Templates())))
+		},
+		func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) },
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "STARTUP") },
+		func(gtx layout.Context) layout.Dimensions { return l.menuButton(gtx, &l.startup, startupLabel) },
+		func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) },
+		func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "HIDDEN APPS") },
+		func(gtx layout.Context) layout.Dimensions {
+			return l.menuButton(gtx, &l.blocklist, fmt.Sprintf("Manage blocklist (%d)", len(apps.Blocklist())))
+		},
+	}
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.heading(gtx, "Settings") }),
 		layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "SEARCH") }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return l.label(gtx, "URL template. %s is replaced with the query.")
-		}),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.input(gtx, editor.Layout) }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "STARTUP") }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.menuButton(gtx, &l.startup, "Add to Startup") }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.separator(gtx) }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.section(gtx, "HIDDEN APPS") }),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return l.menuButton(gtx, &l.clear, fmt.Sprintf("Clear Blocklist (%d)", len(g.ExecBlocklist)))
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(l.theme, &l.settingsList).Layout(gtx, len(sections), func(gtx layout.Context, i int) layout.Dimensions {
+				return sections[i](gtx)
+			})
 		}),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			if !l.confirmClear {
-				return layout.Dimensions{}
-			}
-			return layout.Flex{}.Layout(gtx, layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.menuButton(gtx, &l.confirm, "Confirm clear") }), layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.menuButton(gtx, &l.cancel, "Cancel") }))
-		}),
-		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { return layout.Dimensions{} }),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions { return l.button(gtx, &l.back, "Back") }),
 	)
 }
-func (l *launcher) button(gtx layout.Context, c *widget.Clickable, text string) layout.Dimensions {
-	b := material.Button(l.theme, c, text)
-	b.Background = color.NRGBA{R: 0x46, G: 0x38, B: 0x38, A: 255}
-	b.Color = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-	b.CornerRadius = 0
-	b.TextSize = unit.Sp(10.5)
-	b.Inset = layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(8), Right: unit.Dp(8)}
-	return b.Layout(gtx)
-}
-
-func (l *launcher) menuButton(gtx layout.Context, c *widget.Clickable, text string) layout.Dimensions {
-	return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		return l.button(gtx, c, text)
-	})
-}
-
-func (l *launcher) input(gtx layout.Context, content layout.Widget) layout.Dimensions {
-	return layout.Background{}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		paint.FillShape(gtx.Ops, color.NRGBA{R: 0x2b, G: 0x2b, B: 0x2b, A: 0xff}, clip.Rect{Max: gtx.Constraints.Min}.Op())
-		return layout.Dimensions{Size: gtx.Constraints.Min}
-	}, func(gtx layout.Context) layout.Dimensions {
-		return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5), Left: unit.Dp(6), Right: unit.Dp(6)}.Layout(gtx, content)
-	})
-}
-
-func (l *launcher) section(gtx layout.Context, text string) layout.Dimensions {
-	style := material.Body1(l.theme, text)
-	style.Color = color.NRGBA{R: 0xc8, G: 0xc8, B: 0xc8, A: 0xff}
-	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(3)}.Layout(gtx, style.Layout)
-}
-
-func (l *launcher) separator(gtx layout.Context) layout.Dimensions {
-	size := image.Pt(gtx.Constraints.Max.X, gtx.Dp(unit.Dp(1)))
-	paint.FillShape(gtx.Ops, color.NRGBA{R: 0x4a, G: 0x4a, B: 0x4a, A: 0xff}, clip.Rect{Max: size}.Op())
-	return layout.Dimensions{Size: size}
-}
-func (l *launcher) label(gtx layout.Context, text string) layout.Dimensions {
-	s := material.Body1(l.theme, text)
-	s.Color = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-	return s.Layout(gtx)
-}
-func (l *launcher) heading(gtx layout.Context, text string) layout.Dimensions {
-	s := material.H6(l.theme, text)
-	s.Color = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
-	return s.Layout(gtx)
-}
 func placeholder(mode int) string {
 	switch mode {
 	case g.ModeSearchDocument:
@@ -565,6 +491,8 @@ func placeholder(mode int) string {
 		return "Choose window..."
 	case g.ModeAskGPT:
 		return "Quick GPT..."
+	case g.ModeCommand:
+		return "Tell me what to do..."
 	default:
 		return "Program search..."
 	}
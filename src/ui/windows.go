@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/op/paint"
+	"winfastnav/internal/presentation"
+	"winfastnav/internal/windowmanager"
+)
+
+// windowIcon returns the uploaded icon of a window result, nil if it has none.
+func (l *launcher) windowIcon(r presentation.Result) *paint.ImageOp {
+	if r.Icon == nil {
+		return nil
+	}
+	op, ok := l.windowIcons[r.Window]
+	if !ok {
+		if l.windowIcons == nil {
+			l.windowIcons = make(map[uint64]paint.ImageOp)
+		}
+		op = paint.NewImageOp(r.Icon)
+		l.windowIcons[r.Window] = op
+	}
+	return &op
+}
+
+// windowShortcuts are pressed with Alt in the switcher and act on the selected window.
+// The editor keeps Ctrl with arrows for moving by words.
+var windowShortcuts = map[key.Name]windowmanager.Action{
+	key.NameLeftArrow:  windowmanager.ActionSnapLeft,
+	key.NameRightArrow: windowmanager.ActionSnapRight,
+	key.NameUpArrow:    windowmanager.ActionMaximize,
+	key.NameDownArrow:  windowmanager.ActionMinimize,
+	"M":                windowmanager.ActionNextMonitor,
+	"T":                windowmanager.ActionAlwaysOnTop,
+	"W":                windowmanager.ActionClose,
+	"K":                windowmanager.ActionKill,
+}
+
+func windowShortcutFilters() []event.Filter {
+	var filters []event.Filter
+	for name := range windowShortcuts {
+		filters = append(filters, key.Filter{Name: name, Required: key.ModAlt})
+	}
+	return filters
+}